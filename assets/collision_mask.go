@@ -15,6 +15,7 @@ func buildCollisionMask(spriteLines, collisionLines [][]rune, widthMask [][]int,
 		return nil, fmt.Errorf("sprite and collision sizes differ: sprite=%dx%d collision=%dx%d", sw, sh, cw, ch)
 	}
 	cells := make([]bool, cellW*sh)
+	kinds := make([]rune, cellW*sh)
 	for y := 0; y < sh; y++ {
 		col := 0
 		for x := 0; x < sw; x++ {
@@ -29,6 +30,7 @@ func buildCollisionMask(spriteLines, collisionLines [][]rune, widthMask [][]int,
 				col++
 				if collides {
 					cells[idx] = true
+					kinds[idx] = ch
 				}
 			}
 		}
@@ -36,5 +38,5 @@ func buildCollisionMask(spriteLines, collisionLines [][]rune, widthMask [][]int,
 			col++
 		}
 	}
-	return &render.CollisionMask{W: cellW, H: sh, Cells: cells}, nil
+	return &render.CollisionMask{W: cellW, H: sh, Cells: cells, Kinds: kinds}, nil
 }
@@ -10,13 +10,20 @@ type Camera interface {
 	SetViewport(w, h int)
 }
 
+// Zoomer is implemented by cameras that scale the world. Zoom is screen
+// cells per world cell: 2 doubles everything, 0.5 shows twice as much.
+type Zoomer interface {
+	Zoom() float64
+}
+
 // Basic is a simple top-left anchored camera.
 // X,Y are the world-space coordinates of the top-left screen cell.
 type Basic struct {
-	x float64
-	y float64
-	w int
-	h int
+	x    float64
+	y    float64
+	w    int
+	h    int
+	zoom float64
 }
 
 func NewBasic() *Basic {
@@ -29,12 +36,36 @@ func (c *Basic) Set(x, y float64) {
 }
 
 func (c *Basic) SetCenter(cx, cy float64) {
-	c.x = cx - float64(c.w)/2
-	c.y = cy - float64(c.h)/2
+	vw, vh := c.viewSize()
+	c.x = cx - vw/2
+	c.y = cy - vh/2
 }
 
 func (c *Basic) Center() (float64, float64) {
-	return c.x + float64(c.w)/2, c.y + float64(c.h)/2
+	vw, vh := c.viewSize()
+	return c.x + vw/2, c.y + vh/2
+}
+
+// Zoom returns the zoom factor; 1 unless changed with SetZoom.
+func (c *Basic) Zoom() float64 {
+	if c.zoom <= 0 {
+		return 1
+	}
+	return c.zoom
+}
+
+// SetZoom changes the zoom factor, keeping the view centered on the same
+// world position. Values <= 0 reset it to 1.
+func (c *Basic) SetZoom(z float64) {
+	cx, cy := c.Center()
+	c.zoom = z
+	c.SetCenter(cx, cy)
+}
+
+// viewSize returns the visible area in world cells.
+func (c *Basic) viewSize() (float64, float64) {
+	z := c.Zoom()
+	return float64(c.w) / z, float64(c.h) / z
 }
 
 func (c *Basic) Move(dx, dy float64) {
@@ -53,11 +84,13 @@ func (c *Basic) SetViewport(w, h int) {
 }
 
 func (c *Basic) WorldToScreen(x, y float64) (sx, sy float64) {
-	return x - c.x, y - c.y
+	z := c.Zoom()
+	return (x - c.x) * z, (y - c.y) * z
 }
 
 func (c *Basic) ScreenToWorld(x, y float64) (wx, wy float64) {
-	return x + c.x, y + c.y
+	z := c.Zoom()
+	return x/z + c.x, y/z + c.y
 }
 
 func (c *Basic) Visible(x, y float64, w, h int) bool {
@@ -74,10 +107,11 @@ func (c *Basic) Visible(x, y float64, w, h int) bool {
 	if right <= cx || bottom <= cy {
 		return false
 	}
-	if left >= cx+c.w || top >= cy+c.h {
-		return false
+	if c.Zoom() == 1 {
+		return left < cx+c.w && top < cy+c.h
 	}
-	return true
+	vw, vh := c.viewSize()
+	return float64(left) < c.x+vw && float64(top) < c.y+vh
 }
 
 type Bounds struct {
@@ -94,8 +128,9 @@ func (c *Basic) ClampTo(b Bounds) {
 	if b.W <= 0 || b.H <= 0 || c.w <= 0 || c.h <= 0 {
 		return
 	}
-	maxX := b.X + b.W - float64(c.w)
-	maxY := b.Y + b.H - float64(c.h)
+	vw, vh := c.viewSize()
+	maxX := b.X + b.W - vw
+	maxY := b.Y + b.H - vh
 	if maxX < b.X {
 		maxX = b.X
 	}
@@ -114,11 +149,11 @@ func (c *Basic) ClampTo(b Bounds) {
 	if c.y > maxY {
 		c.y = maxY
 	}
-	if b.W < float64(c.w) {
-		c.x = b.X + (b.W-float64(c.w))/2
+	if b.W < vw {
+		c.x = b.X + (b.W-vw)/2
 	}
-	if b.H < float64(c.h) {
-		c.y = b.Y + (b.H-float64(c.h))/2
+	if b.H < vh {
+		c.y = b.Y + (b.H-vh)/2
 	}
 }
 
@@ -0,0 +1,125 @@
+package camera
+
+import (
+	"math"
+	"testing"
+)
+
+// TestFollowDeadzone verifies the camera only moves once the target leaves the deadzone.
+func TestFollowDeadzone(t *testing.T) {
+	cam := NewBasic()
+	cam.SetViewport(20, 10)
+	x, y := 50.0, 50.0
+	f := NewFollow(cam, func() (float64, float64, bool) { return x, y, true })
+	f.Smoothing = 0
+	f.DeadzoneW = 6
+	f.DeadzoneH = 4
+	f.Update(0)
+	if cx, cy := cam.Center(); cx != 50 || cy != 50 {
+		t.Fatalf("center=%v,%v want 50,50", cx, cy)
+	}
+
+	x = 52
+	f.Update(0.1)
+	if cx, _ := cam.Center(); cx != 50 {
+		t.Fatalf("camera moved inside deadzone: cx=%v", cx)
+	}
+	x = 58
+	f.Update(0.1)
+	if cx, _ := cam.Center(); cx != 55 {
+		t.Fatalf("cx=%v want 55 (target on deadzone edge)", cx)
+	}
+
+	bounds := Bounds{X: 0, Y: 0, W: 60, H: 60}
+	f.Bounds = &bounds
+	x = 100
+	f.Update(0.1)
+	if px, _ := cam.Position(); px != 40 {
+		t.Fatalf("x=%v want 40 (clamped)", px)
+	}
+}
+
+// TestFollowSmoothing verifies smoothing eases toward the target without overshooting.
+func TestFollowSmoothing(t *testing.T) {
+	cam := NewBasic()
+	cam.SetViewport(20, 10)
+	x := 0.0
+	f := NewFollow(cam, func() (float64, float64, bool) { return x, 0, true })
+	f.Update(0)
+	x = 10
+	f.Update(0.1)
+	cx, _ := cam.Center()
+	want := 10 * (1 - math.Exp(-f.Smoothing*0.1))
+	if math.Abs(cx-want) > 1e-9 {
+		t.Fatalf("cx=%v want %v", cx, want)
+	}
+	for i := 0; i < 100; i++ {
+		f.Update(0.1)
+	}
+	if cx, _ := cam.Center(); math.Abs(cx-10) > 1e-6 {
+		t.Fatalf("cx=%v want 10", cx)
+	}
+}
+
+// TestShake verifies shake offsets projection both ways without moving the camera, then decays.
+func TestShake(t *testing.T) {
+	cam := NewBasic()
+	cam.SetViewport(20, 10)
+	cam.Set(5, 5)
+	s := NewShake(cam)
+	s.AddTrauma(2)
+	if s.Trauma() != 1 {
+		t.Fatalf("trauma=%v want 1 (capped)", s.Trauma())
+	}
+	s.Update(0.1)
+	ox, oy := s.Offset()
+	if ox == 0 && oy == 0 {
+		t.Fatalf("expected a non-zero offset")
+	}
+	if math.Abs(ox) > s.MaxX || math.Abs(oy) > s.MaxY {
+		t.Fatalf("offset=%v,%v exceeds max", ox, oy)
+	}
+	sx, sy := s.WorldToScreen(10, 10)
+	if sx != 5+ox || sy != 5+oy {
+		t.Fatalf("screen=%v,%v", sx, sy)
+	}
+	if wx, wy := s.ScreenToWorld(sx, sy); math.Abs(wx-10) > 1e-9 || math.Abs(wy-10) > 1e-9 {
+		t.Fatalf("round trip=%v,%v want 10,10", wx, wy)
+	}
+	if px, py := cam.Position(); px != 5 || py != 5 {
+		t.Fatalf("camera moved to %v,%v", px, py)
+	}
+
+	s.Update(1)
+	if s.Trauma() != 0 {
+		t.Fatalf("trauma=%v want 0 after decay", s.Trauma())
+	}
+	if ox, oy := s.Offset(); ox != 0 || oy != 0 {
+		t.Fatalf("offset=%v,%v want 0,0", ox, oy)
+	}
+}
+
+// TestBasicZoom verifies zoom keeps the center and scales projection, visibility and clamping.
+func TestBasicZoom(t *testing.T) {
+	cam := NewBasic()
+	cam.SetViewport(20, 10)
+	cam.SetCenter(50, 50)
+	cam.SetZoom(0.5)
+	if cx, cy := cam.Center(); cx != 50 || cy != 50 {
+		t.Fatalf("center=%v,%v want 50,50", cx, cy)
+	}
+	if x, y := cam.Position(); x != 30 || y != 40 {
+		t.Fatalf("position=%v,%v want 30,40", x, y)
+	}
+	if sx, sy := cam.WorldToScreen(40, 50); sx != 5 || sy != 5 {
+		t.Fatalf("screen=%v,%v want 5,5", sx, sy)
+	}
+	if !cam.Visible(65, 55, 1, 1) || cam.Visible(70, 55, 1, 1) {
+		t.Fatalf("visibility should cover 40 world cells")
+	}
+	cam.Set(90, 90)
+	cam.ClampTo(Bounds{W: 100, H: 100})
+	if x, y := cam.Position(); x != 60 || y != 80 {
+		t.Fatalf("clamped=%v,%v want 60,80", x, y)
+	}
+}
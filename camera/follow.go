@@ -0,0 +1,126 @@
+package camera
+
+import "math"
+
+// Target reports the world position a Follow should track. ok=false holds
+// the camera where it is, e.g. while the followed entity is gone.
+type Target func() (x, y float64, ok bool)
+
+// Point returns a Target fixed at x,y.
+func Point(x, y float64) Target {
+	return func() (float64, float64, bool) {
+		return x, y, true
+	}
+}
+
+// Follow moves a Basic camera to keep a target in view.
+//
+// The target may roam inside a deadzone centered on the screen without
+// moving the camera. Once it leaves, the camera is pulled along so the
+// target sits on the deadzone edge. LookAhead shifts the aim point along the
+// target's velocity, and Smoothing eases the camera toward its goal.
+type Follow struct {
+	Camera *Basic
+	Target Target
+
+	// DeadzoneW and DeadzoneH size the deadzone in world cells. Zero
+	// keeps the target centered.
+	DeadzoneW float64
+	DeadzoneH float64
+	// Smoothing is how quickly the camera catches up, per second. Zero
+	// snaps to the goal every update.
+	Smoothing float64
+	// LookAhead is how many seconds of target velocity to lead by.
+	LookAhead float64
+	// Bounds, when non-nil, clamps the camera with Basic.ClampTo.
+	Bounds *Bounds
+
+	started bool
+	goalX   float64
+	goalY   float64
+	prevX   float64
+	prevY   float64
+	leadX   float64
+	leadY   float64
+}
+
+func NewFollow(cam *Basic, target Target) *Follow {
+	return &Follow{
+		Camera:    cam,
+		Target:    target,
+		Smoothing: 8,
+	}
+}
+
+// Update moves the camera toward the target. Call it once per update after
+// the target has moved.
+func (f *Follow) Update(dt float64) {
+	if f == nil || f.Camera == nil || f.Target == nil {
+		return
+	}
+	tx, ty, ok := f.Target()
+	if !ok {
+		return
+	}
+	if !f.started {
+		f.started = true
+		f.prevX, f.prevY = tx, ty
+		f.goalX, f.goalY = tx, ty
+		f.leadX, f.leadY = 0, 0
+		f.apply(tx, ty)
+		return
+	}
+	if dt <= 0 {
+		return
+	}
+
+	vx := (tx - f.prevX) / dt
+	vy := (ty - f.prevY) / dt
+	f.prevX, f.prevY = tx, ty
+	ease := f.ease(dt)
+	f.leadX += (vx*f.LookAhead - f.leadX) * ease
+	f.leadY += (vy*f.LookAhead - f.leadY) * ease
+
+	aimX := tx + f.leadX
+	aimY := ty + f.leadY
+	f.goalX = pullInto(f.goalX, aimX, f.DeadzoneW/2)
+	f.goalY = pullInto(f.goalY, aimY, f.DeadzoneH/2)
+
+	cx, cy := f.Camera.Center()
+	f.apply(cx+(f.goalX-cx)*ease, cy+(f.goalY-cy)*ease)
+}
+
+// Snap jumps straight to the target, dropping smoothing and look-ahead
+// state. Use it after teleports or level loads.
+func (f *Follow) Snap() {
+	if f == nil {
+		return
+	}
+	f.started = false
+	f.Update(0)
+}
+
+func (f *Follow) ease(dt float64) float64 {
+	if f.Smoothing <= 0 {
+		return 1
+	}
+	return 1 - math.Exp(-f.Smoothing*dt)
+}
+
+func (f *Follow) apply(cx, cy float64) {
+	f.Camera.SetCenter(cx, cy)
+	if f.Bounds != nil {
+		f.Camera.ClampTo(*f.Bounds)
+	}
+}
+
+// pullInto moves center the least amount that puts p within half of it.
+func pullInto(center, p, half float64) float64 {
+	if p < center-half {
+		return p + half
+	}
+	if p > center+half {
+		return p - half
+	}
+	return center
+}
@@ -0,0 +1,120 @@
+package camera
+
+import "math"
+
+// Shake wraps a Camera and offsets everything it projects by a decaying,
+// trauma-driven noise. The wrapped camera's position is never changed, so
+// shake layers cleanly on top of Follow or manual panning.
+//
+// Trauma is in [0, 1]; the offset scales with trauma squared so small hits
+// barely register while big ones jolt.
+type Shake struct {
+	Camera Camera
+
+	// MaxX and MaxY are the largest offsets, in cells, at full trauma.
+	MaxX float64
+	MaxY float64
+	// Decay is how much trauma is lost per second.
+	Decay float64
+	// Frequency is how fast the noise changes, in cycles per second.
+	Frequency float64
+	// Seed varies the noise between shakes that would otherwise match.
+	Seed float64
+
+	trauma  float64
+	elapsed float64
+	ox      float64
+	oy      float64
+}
+
+// NewShake wraps cam. A nil cam behaves like an identity camera.
+func NewShake(cam Camera) *Shake {
+	return &Shake{
+		Camera:    cam,
+		MaxX:      2,
+		MaxY:      1,
+		Decay:     1.5,
+		Frequency: 12,
+	}
+}
+
+// AddTrauma adds to the current trauma, capped at 1.
+func (s *Shake) AddTrauma(amount float64) {
+	s.trauma = math.Max(0, math.Min(1, s.trauma+amount))
+}
+
+func (s *Shake) Trauma() float64 {
+	return s.trauma
+}
+
+// Update advances the noise and decays trauma. Call it once per update.
+func (s *Shake) Update(dt float64) {
+	s.elapsed += dt
+	s.trauma = math.Max(0, s.trauma-s.Decay*dt)
+	if s.trauma == 0 {
+		s.ox, s.oy = 0, 0
+		return
+	}
+	amount := s.trauma * s.trauma
+	t := s.elapsed * s.Frequency
+	s.ox = s.MaxX * amount * shakeNoise(t, s.Seed)
+	s.oy = s.MaxY * amount * shakeNoise(t, s.Seed+17.3)
+}
+
+// Offset returns the current screen offset in cells.
+func (s *Shake) Offset() (float64, float64) {
+	return s.ox, s.oy
+}
+
+func (s *Shake) WorldToScreen(x, y float64) (sx, sy float64) {
+	if s.Camera != nil {
+		x, y = s.Camera.WorldToScreen(x, y)
+	}
+	return x + s.ox, y + s.oy
+}
+
+func (s *Shake) ScreenToWorld(x, y float64) (wx, wy float64) {
+	x -= s.ox
+	y -= s.oy
+	if s.Camera != nil {
+		return s.Camera.ScreenToWorld(x, y)
+	}
+	return x, y
+}
+
+func (s *Shake) Visible(x, y float64, w, h int) bool {
+	if s.Camera == nil {
+		return w > 0 && h > 0
+	}
+	// Offsets are in screen cells; convert them to world cells.
+	z := s.Zoom()
+	return s.Camera.Visible(x+s.ox/z, y+s.oy/z, w, h)
+}
+
+// Zoom reports the wrapped camera's zoom, or 1.
+func (s *Shake) Zoom() float64 {
+	if z, ok := s.Camera.(Zoomer); ok {
+		return z.Zoom()
+	}
+	return 1
+}
+
+func (s *Shake) SetViewport(w, h int) {
+	if s.Camera != nil {
+		s.Camera.SetViewport(w, h)
+	}
+}
+
+// Viewport reports the wrapped camera's viewport size, or 0,0 if it does
+// not expose one.
+func (s *Shake) Viewport() (int, int) {
+	if v, ok := s.Camera.(interface{ Viewport() (int, int) }); ok {
+		return v.Viewport()
+	}
+	return 0, 0
+}
+
+// shakeNoise is smooth noise in [-1, 1] built from incommensurate sines.
+func shakeNoise(t, seed float64) float64 {
+	return (math.Sin(t+seed) + 0.6*math.Sin(2.31*t+1.7*seed) + 0.3*math.Sin(4.73*t+2.9*seed)) / 1.9
+}
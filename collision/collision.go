@@ -5,6 +5,10 @@ import "github.com/dgrundel/glif/render"
 // Overlaps returns true when two sprites' collision masks overlap in world space.
 // If either sprite has no collision mask, Overlaps returns false.
 func Overlaps(ax, ay int, a *render.Sprite, bx, by int, b *render.Sprite) bool {
+	return overlapKinds(ax, ay, a, "", bx, by, b, "")
+}
+
+func overlapKinds(ax, ay int, a *render.Sprite, aKinds string, bx, by int, b *render.Sprite, bKinds string) bool {
 	if a == nil || b == nil || a.Collision == nil || b.Collision == nil {
 		return false
 	}
@@ -19,7 +23,7 @@ func Overlaps(ax, ay int, a *render.Sprite, bx, by int, b *render.Sprite) bool {
 
 	for y := top; y < bottom; y++ {
 		for x := left; x < right; x++ {
-			if a.Collision.At(x-ax, y-ay) && b.Collision.At(x-bx, y-by) {
+			if a.Collision.AtKind(x-ax, y-ay, aKinds) && b.Collision.AtKind(x-bx, y-by, bKinds) {
 				return true
 			}
 		}
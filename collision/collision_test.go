@@ -0,0 +1,114 @@
+package collision
+
+import (
+	"testing"
+
+	"github.com/dgrundel/glif/render"
+	"github.com/dgrundel/glif/tilemap"
+)
+
+func solidSprite(w, h int) *render.Sprite {
+	cells := make([]bool, w*h)
+	for i := range cells {
+		cells[i] = true
+	}
+	return &render.Sprite{W: w, H: h, Collision: &render.CollisionMask{W: w, H: h, Cells: cells}}
+}
+
+// TestSweepHitsThinTarget verifies a fast mover cannot tunnel through a 1-cell wall.
+func TestSweepHitsThinTarget(t *testing.T) {
+	bullet := solidSprite(1, 1)
+	wall := solidSprite(1, 3)
+
+	if Overlaps(0, 1, bullet, 5, 0, wall) || Overlaps(10, 1, bullet, 5, 0, wall) {
+		t.Fatalf("endpoints should not overlap")
+	}
+	toi, hit := Sweep(bullet, Point{X: 0, Y: 1}, Point{X: 10, Y: 1}, 5, 0, wall)
+	if !hit {
+		t.Fatalf("Sweep missed wall")
+	}
+	if toi != 0.5 {
+		t.Fatalf("toi=%v want=0.5", toi)
+	}
+
+	if _, hit := Sweep(bullet, Point{X: 0, Y: 5}, Point{X: 10, Y: 5}, 5, 0, wall); hit {
+		t.Fatalf("Sweep hit wall on a path that passes below it")
+	}
+}
+
+// TestRaycastTilesAndBodies verifies the closest of a tile or body is returned.
+func TestRaycastTilesAndBodies(t *testing.T) {
+	m := tilemap.New(4, 1, 2, 1, 0)
+	m.Tileset[1] = solidSprite(2, 1)
+	m.Set(3, 0, 1)
+
+	hit, ok := Raycast(m, 0, 0, nil, 0.5, 0.5, 1, 0, 20)
+	if !ok || !hit.Tile || hit.X != 6 {
+		t.Fatalf("hit=%+v ok=%v want tile at x=6", hit, ok)
+	}
+	if hit.Distance != 5.5 {
+		t.Fatalf("Distance=%v want=5.5", hit.Distance)
+	}
+
+	bodies := []Body{{ID: 7, X: 3, Y: 0, Sprite: solidSprite(1, 1)}}
+	hit, ok = Raycast(m, 0, 0, bodies, 0.5, 0.5, 1, 0, 20)
+	if !ok || hit.Body == nil || hit.Body.ID != 7 || hit.X != 3 {
+		t.Fatalf("hit=%+v ok=%v want body 7 at x=3", hit, ok)
+	}
+
+	if _, ok := Raycast(m, 0, 0, bodies, 0.5, 0.5, 1, 0, 2); ok {
+		t.Fatalf("Raycast should stop at maxDist")
+	}
+}
+
+// TestOverlapsFiltered verifies category masks and per-character layers.
+func TestOverlapsFiltered(t *testing.T) {
+	enemy := &render.Sprite{W: 2, H: 1, Collision: &render.CollisionMask{
+		W: 2, H: 1, Cells: []bool{true, true}, Kinds: []rune{'s', 'h'},
+	}}
+	bullet := solidSprite(1, 1)
+
+	const (
+		layerBullet Layer = 1 << 1
+		layerEnemy  Layer = 1 << 2
+	)
+	bf := Filter{Category: layerBullet, Mask: layerEnemy}
+	ef := Filter{Category: layerEnemy, Mask: layerBullet, Kinds: "h"}
+
+	if OverlapsFiltered(0, 0, bullet, bf, 0, 0, enemy, ef) {
+		t.Fatalf("bullet should not hit the solid cell when only the hurtbox is enabled")
+	}
+	if !OverlapsFiltered(1, 0, bullet, bf, 0, 0, enemy, ef) {
+		t.Fatalf("bullet should hit the hurtbox cell")
+	}
+	if OverlapsFiltered(1, 0, bullet, bf, 0, 0, bullet, bf) {
+		t.Fatalf("bullets should not hit each other")
+	}
+	if !OverlapsFiltered(0, 0, bullet, Filter{}, 0, 0, enemy, Filter{}) {
+		t.Fatalf("zero filters should collide")
+	}
+}
+
+// TestChunkedTiles verifies tile collision on a chunked map with negative
+// coordinates, without generating chunks.
+func TestChunkedTiles(t *testing.T) {
+	c := tilemap.NewChunked(2, 2, 2, 1, 0, func(coord tilemap.ChunkCoord, chunk *tilemap.Map) {
+		chunk.Set(0, 0, 1)
+	})
+	c.Tileset[1] = solidSprite(2, 1)
+	c.Chunk(tilemap.ChunkCoord{X: -1, Y: -1})
+
+	hits := TileHits(c, 0, 0, -4, -2, solidSprite(3, 1))
+	if len(hits) != 1 || hits[0] != (TileHit{TX: -2, TY: -2, ID: 1}) {
+		t.Fatalf("hits=%+v want tile -2,-2", hits)
+	}
+	if Blocked(c, 0, 0, -2, -2, solidSprite(1, 1)) {
+		t.Fatalf("empty tile should not block")
+	}
+	if _, ok := Raycast(c, 0, 0, nil, 0.5, 0.5, 1, 0, 50); ok {
+		t.Fatalf("ray through unloaded chunks should not hit")
+	}
+	if len(c.Chunks()) != 1 {
+		t.Fatalf("collision queries generated chunks: %v", c.Chunks())
+	}
+}
@@ -0,0 +1,61 @@
+package collision
+
+import "github.com/dgrundel/glif/render"
+
+// Layer is a bit set of collision categories.
+type Layer uint32
+
+const (
+	LayerDefault Layer = 1
+	LayerAll     Layer = ^Layer(0)
+)
+
+// Filter decides which objects may collide.
+// Category is the set of layers an object belongs to and Mask is the set of
+// layers it collides with. Kinds optionally restricts the sprite's mask to
+// cells drawn with those `.collision` characters (e.g. "h" for a hurtbox).
+// The zero Filter belongs to LayerDefault and collides with everything.
+// Only the all-zero Filter gets those defaults: a Filter with a Category but
+// no Mask collides with nothing, so set both, e.g. Mask: LayerAll.
+type Filter struct {
+	Category Layer
+	Mask     Layer
+	Kinds    string
+}
+
+func (f Filter) normalized() Filter {
+	if f.Category == 0 && f.Mask == 0 {
+		f.Category = LayerDefault
+		f.Mask = LayerAll
+	}
+	return f
+}
+
+// Accepts reports whether objects using f and other are allowed to collide.
+// Both sides must include the other's category in their mask.
+func (f Filter) Accepts(other Filter) bool {
+	a := f.normalized()
+	b := other.normalized()
+	return a.Mask&b.Category != 0 && b.Mask&a.Category != 0
+}
+
+// OverlapsFiltered is like Overlaps but only reports a hit when the filters
+// accept each other and the overlapping cells are on each filter's Kinds.
+func OverlapsFiltered(ax, ay int, a *render.Sprite, af Filter, bx, by int, b *render.Sprite, bf Filter) bool {
+	if !af.Accepts(bf) {
+		return false
+	}
+	return overlapKinds(ax, ay, a, af.Kinds, bx, by, b, bf.Kinds)
+}
+
+// Query returns the bodies that overlap sprite s at x,y and pass filter f.
+func Query(bodies []Body, x, y int, s *render.Sprite, f Filter) []*Body {
+	var hits []*Body
+	for i := range bodies {
+		b := &bodies[i]
+		if OverlapsFiltered(x, y, s, f, b.X, b.Y, b.Sprite, b.Filter) {
+			hits = append(hits, b)
+		}
+	}
+	return hits
+}
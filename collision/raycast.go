@@ -0,0 +1,91 @@
+package collision
+
+import (
+	"math"
+
+	"github.com/dgrundel/glif/render"
+	"github.com/dgrundel/glif/tilemap"
+)
+
+// Body is a sprite placed in world space that rays and queries can hit.
+// ID is an opaque caller value (e.g. an ecs.Entity) returned in Hit.
+type Body struct {
+	ID     int
+	X      int
+	Y      int
+	Sprite *render.Sprite
+	Filter Filter
+}
+
+// Hit describes the first collidable cell found by Raycast.
+// Body is nil when the ray stopped on a tile.
+type Hit struct {
+	X        int
+	Y        int
+	Distance float64
+	Tile     bool
+	Body     *Body
+}
+
+// Raycast walks the world cells crossed by a ray using grid DDA and returns the
+// first cell that collides with a tile in m (drawn at mapX,mapY) or with one of
+// bodies. The direction does not need to be normalized. Rays stop after
+// maxDist cells. m may be nil to test bodies only.
+func Raycast(m tilemap.Tiles, mapX, mapY int, bodies []Body, ox, oy, dx, dy, maxDist float64) (Hit, bool) {
+	return RaycastFiltered(m, mapX, mapY, bodies, Filter{}, ox, oy, dx, dy, maxDist)
+}
+
+// RaycastFiltered is like Raycast but skips bodies whose filter does not accept f.
+func RaycastFiltered(m tilemap.Tiles, mapX, mapY int, bodies []Body, f Filter, ox, oy, dx, dy, maxDist float64) (Hit, bool) {
+	length := math.Hypot(dx, dy)
+	if length == 0 || maxDist <= 0 {
+		return Hit{}, false
+	}
+	dx /= length
+	dy /= length
+
+	x := int(math.Floor(ox))
+	y := int(math.Floor(oy))
+	stepX, tMaxX, tDeltaX := ddaAxis(ox, dx)
+	stepY, tMaxY, tDeltaY := ddaAxis(oy, dy)
+
+	dist := 0.0
+	for dist <= maxDist {
+		if _, _, solid := tileSolidAt(m, mapX, mapY, x, y); solid {
+			return Hit{X: x, Y: y, Distance: dist, Tile: true}, true
+		}
+		for i := range bodies {
+			if f.Accepts(bodies[i].Filter) && bodySolidAt(&bodies[i], x, y) {
+				return Hit{X: x, Y: y, Distance: dist, Body: &bodies[i]}, true
+			}
+		}
+		if tMaxX < tMaxY {
+			dist = tMaxX
+			tMaxX += tDeltaX
+			x += stepX
+		} else {
+			dist = tMaxY
+			tMaxY += tDeltaY
+			y += stepY
+		}
+	}
+	return Hit{}, false
+}
+
+func ddaAxis(origin, dir float64) (int, float64, float64) {
+	if dir == 0 {
+		return 0, math.Inf(1), math.Inf(1)
+	}
+	cell := math.Floor(origin)
+	if dir > 0 {
+		return 1, (cell + 1 - origin) / dir, 1 / dir
+	}
+	return -1, (origin - cell) / -dir, 1 / -dir
+}
+
+func bodySolidAt(b *Body, x, y int) bool {
+	if b == nil || b.Sprite == nil || b.Sprite.Collision == nil {
+		return false
+	}
+	return b.Sprite.Collision.AtKind(x-b.X, y-b.Y, b.Filter.Kinds)
+}
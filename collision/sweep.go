@@ -0,0 +1,43 @@
+package collision
+
+import (
+	"math"
+
+	"github.com/dgrundel/glif/render"
+)
+
+// Point is a position in world space.
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Sweep moves sprite a from one position to another and reports the earliest
+// time of impact against sprite b at bx,by. The time is in the range [0,1],
+// where 0 is from and 1 is to. The path is sampled once per cell travelled so
+// fast movers cannot skip over thin masks between steps.
+func Sweep(a *render.Sprite, from, to Point, bx, by int, b *render.Sprite) (float64, bool) {
+	if a == nil || b == nil || a.Collision == nil || b.Collision == nil {
+		return 0, false
+	}
+	dx := to.X - from.X
+	dy := to.Y - from.Y
+	steps := int(math.Ceil(math.Max(math.Abs(dx), math.Abs(dy))))
+	if steps < 1 {
+		steps = 1
+	}
+	lastX, lastY := math.MinInt, math.MinInt
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		ax := int(math.Floor(from.X + dx*t))
+		ay := int(math.Floor(from.Y + dy*t))
+		if ax == lastX && ay == lastY {
+			continue
+		}
+		lastX, lastY = ax, ay
+		if Overlaps(ax, ay, a, bx, by, b) {
+			return t, true
+		}
+	}
+	return 0, false
+}
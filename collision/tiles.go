@@ -0,0 +1,85 @@
+package collision
+
+import (
+	"sort"
+
+	"github.com/dgrundel/glif/render"
+	"github.com/dgrundel/glif/tilemap"
+)
+
+// TileHit identifies a map tile that blocks a sprite.
+type TileHit struct {
+	TX int
+	TY int
+	ID int
+}
+
+// TileHits returns the tiles of m (drawn at mapX,mapY) that overlap sprite s at
+// x,y, in row-major tile order. A tile with a `solid` property blocks on its
+// whole area when true and never blocks when false; otherwise the collision
+// mask of the tile's current sprite is used. Sprites without a collision mask
+// hit nothing.
+func TileHits(m tilemap.Tiles, mapX, mapY, x, y int, s *render.Sprite) []TileHit {
+	if m == nil || s == nil || s.Collision == nil {
+		return nil
+	}
+	var hits []TileHit
+	seen := map[[2]int]bool{}
+	for row := 0; row < s.Collision.H; row++ {
+		for col := 0; col < s.Collision.W; col++ {
+			if !s.Collision.At(col, row) {
+				continue
+			}
+			tx, ty, solid := tileSolidAt(m, mapX, mapY, x+col, y+row)
+			if !solid || seen[[2]int{tx, ty}] {
+				continue
+			}
+			seen[[2]int{tx, ty}] = true
+			hits = append(hits, TileHit{TX: tx, TY: ty, ID: m.At(tx, ty)})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].TY == hits[j].TY {
+			return hits[i].TX < hits[j].TX
+		}
+		return hits[i].TY < hits[j].TY
+	})
+	return hits
+}
+
+// Blocked reports whether sprite s at x,y overlaps any blocking tile in m.
+func Blocked(m tilemap.Tiles, mapX, mapY, x, y int, s *render.Sprite) bool {
+	return len(TileHits(m, mapX, mapY, x, y, s)) > 0
+}
+
+// tileSolidAt returns the tile under world cell x,y and whether that cell
+// blocks.
+func tileSolidAt(m tilemap.Tiles, mapX, mapY, x, y int) (tx, ty int, solid bool) {
+	if m == nil {
+		return 0, 0, false
+	}
+	tw, th := m.TileSize()
+	lx := x - mapX
+	ly := y - mapY
+	tx = floorDiv(lx, tw)
+	ty = floorDiv(ly, th)
+	if !m.InBounds(tx, ty) {
+		return tx, ty, false
+	}
+	if props := m.Props(tx, ty); props.Has("solid") {
+		return tx, ty, props.Bool("solid", false)
+	}
+	sprite := m.Sprite(tx, ty)
+	if sprite == nil || sprite.Collision == nil {
+		return tx, ty, false
+	}
+	return tx, ty, sprite.Collision.At(lx-tx*tw, ly-ty*th)
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
@@ -7,7 +7,7 @@ import (
 	"math/rand"
 	"time"
 
-	"github.com/dgrundel/glif/assets"
+	"github.com/dgrundel/glif/camera"
 	"github.com/dgrundel/glif/collision"
 	"github.com/dgrundel/glif/ecs"
 	"github.com/dgrundel/glif/engine"
@@ -20,7 +20,6 @@ import (
 const (
 	shipSpeed        = 30.0
 	enemySpeed       = 6.0
-	bulletSpeed      = 45.0
 	fireCooldown     = 0.35
 	explodeFPS       = 12.0
 	flyInSpeed       = 28.0
@@ -32,20 +31,21 @@ const (
 	enemyGapX        = 2
 	enemyGapY        = 2
 	enemyStartY      = 2
+	hitTrauma        = 0.25
+	killTrauma       = 0.5
 )
 
 type Game struct {
 	world   *ecs.World
+	shake   *camera.Shake
 	ship    ecs.Entity
 	enemies []ecs.Entity
-	bullets []ecs.Entity
 
-	shipSprite    *render.Sprite
-	enemySprite   *render.Sprite
-	enemy2Sprite  *render.Sprite
-	bulletSprite  *render.Sprite
-	enemyDestroy  *render.Animation
-	enemy2Destroy *render.Animation
+	shipSprite   *render.Sprite
+	enemySprite  *render.Sprite
+	enemy2Sprite *render.Sprite
+	bulletSprite *render.Sprite
+	destroyAnims map[string]*render.Animation // by prefab name
 
 	screenW   int
 	screenH   int
@@ -57,6 +57,7 @@ type Game struct {
 	shipPlaced    bool
 	enemiesPlaced bool
 	level         int
+	status        string // last error, shown under the level; stderr would garble the screen
 
 	binds      input.ActionMap
 	actions    input.ActionState
@@ -64,18 +65,24 @@ type Game struct {
 	levelStyle grid.Style
 	quit       bool
 
-	explosions   []explosion
-	enemyAnims   map[ecs.Entity]*render.Animation
-	enemyTargets map[ecs.Entity]float64
-	enemyHP      map[ecs.Entity]int
-	rng          *rand.Rand
+	rng *rand.Rand
 }
 
-type explosion struct {
-	entity  ecs.Entity
-	frames  []*render.Sprite
-	elapsed float64
-}
+// Enemy components.
+type (
+	health      struct{ HP int }
+	destroyAnim struct{ anim *render.Animation }
+	flyIn       struct{ targetX float64 }
+	exploding   struct{}
+	projectile  struct{}
+)
+
+// Events.
+type (
+	enemyHit     struct{ entity ecs.Entity }
+	enemyKilled  struct{ entity ecs.Entity }
+	levelCleared struct{ level int }
+)
 
 func NewGame() *Game {
 	pal, err := palette.Load("demos/invaders/assets/default.palette")
@@ -92,17 +99,25 @@ func NewGame() *Game {
 	}
 
 	world := ecs.NewWorld()
-	shipSprite := assets.MustLoadSprite("demos/invaders/assets/ship")
-	enemySprite := assets.MustLoadSprite("demos/invaders/assets/enemy")
-	enemy2Sprite := assets.MustLoadSprite("demos/invaders/assets/enemy2")
-	bulletSprite := assets.MustLoadSprite("demos/invaders/assets/bullet")
-	enemyDestroy, err := enemySprite.LoadAnimation("destroy")
-	if err != nil {
-		log.Printf("load destroy animation: %v", err)
+	shake := camera.NewShake(camera.NewBasic())
+	world.Camera = shake
+	if err := world.LoadPrefabs("demos/invaders/assets/invaders.prefabs"); err != nil {
+		log.Fatal(err)
 	}
-	enemy2Destroy, err := enemy2Sprite.LoadAnimation("destroy")
-	if err != nil {
-		log.Printf("load enemy2 destroy animation: %v", err)
+	shipSprite := mustPrefabSprite(world, "ship")
+	enemySprite := mustPrefabSprite(world, "enemy")
+	enemy2Sprite := mustPrefabSprite(world, "enemy2")
+	bulletSprite := mustPrefabSprite(world, "bullet")
+	destroyAnims := map[string]*render.Animation{}
+	var status string
+	for _, name := range []string{"enemy", "enemy2"} {
+		sprite, err := world.PrefabSprite(name)
+		if err == nil {
+			destroyAnims[name], err = sprite.LoadAnimation(world.Prefabs[name].Props.String("destroy", ""))
+		}
+		if err != nil {
+			status = fmt.Sprintf("load %s destroy animation: %v", name, err)
+		}
 	}
 	enemyMaxW := enemySprite.W
 	enemyMaxH := enemySprite.H
@@ -113,24 +128,24 @@ func NewGame() *Game {
 		enemyMaxH = enemy2Sprite.H
 	}
 
-	ship := world.NewEntity()
-	world.AddPosition(ship, 0, 0)
-	world.AddVelocity(ship, 0, 0)
-	world.AddSprite(ship, shipSprite, 1)
-
-	return &Game{
-		world:         world,
-		ship:          ship,
-		shipSprite:    shipSprite,
-		enemySprite:   enemySprite,
-		enemy2Sprite:  enemy2Sprite,
-		bulletSprite:  bulletSprite,
-		enemyDestroy:  enemyDestroy,
-		enemy2Destroy: enemy2Destroy,
-		enemyMaxW:     enemyMaxW,
-		enemyMaxH:     enemyMaxH,
-		enemyDir:      1,
-		level:         1,
+	ship, err := world.Instantiate("ship", 0, 0, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	g := &Game{
+		world:        world,
+		shake:        shake,
+		ship:         ship,
+		shipSprite:   shipSprite,
+		enemySprite:  enemySprite,
+		enemy2Sprite: enemy2Sprite,
+		bulletSprite: bulletSprite,
+		destroyAnims: destroyAnims,
+		enemyMaxW:    enemyMaxW,
+		enemyMaxH:    enemyMaxH,
+		enemyDir:     1,
+		level:        1,
 		binds: input.ActionMap{
 			"move_left":  "key:left",
 			"move_right": "key:right",
@@ -138,13 +153,44 @@ func NewGame() *Game {
 			"quit":       "key:esc",
 			"quit_alt":   "key:ctrl+c",
 		},
-		bg:           bg,
-		levelStyle:   levelStyle,
-		enemyAnims:   make(map[ecs.Entity]*render.Animation),
-		enemyTargets: make(map[ecs.Entity]float64),
-		enemyHP:      make(map[ecs.Entity]int),
-		rng:          rand.New(rand.NewSource(time.Now().UnixNano())),
+		bg:         bg,
+		levelStyle: levelStyle,
+		status:     status,
+		rng:        rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
+	g.addSystems()
+	return g
+}
+
+// addSystems runs the game logic inside world.Update: input before
+// movement, hits right after it, and level checks once hits are handled.
+// Bullet culling and explosions use the stock ecs systems.
+func (g *Game) addSystems() {
+	systems := []ecs.System{
+		{Name: "controls", Phase: ecs.PreUpdate, Run: g.updateControls},
+		{Name: "clamp", Phase: ecs.Update, After: []string{"movement"}, Run: g.clampShip},
+		{Name: "hits", Phase: ecs.Update, After: []string{"clamp"}, Run: g.resolveHits},
+		{Name: "shake", Phase: ecs.PostUpdate, Run: func(w *ecs.World, dt float64) { g.shake.Update(dt) }},
+		{Name: "levels", Phase: ecs.PostUpdate, Run: g.checkNextLevel},
+	}
+	for _, sys := range systems {
+		if err := g.world.Systems.Add(sys); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	ecs.Subscribe(g.world, func(w *ecs.World, ev enemyHit) {
+		g.shake.AddTrauma(hitTrauma)
+	})
+	ecs.Subscribe(g.world, func(w *ecs.World, ev enemyKilled) {
+		g.shake.AddTrauma(killTrauma)
+		g.explode(ev.entity)
+	})
+	ecs.Subscribe(g.world, func(w *ecs.World, ev levelCleared) {
+		g.level = ev.level + 1
+		g.enemiesPlaced = false
+		g.layoutEnemies()
+	})
 }
 
 func (g *Game) Update(dt float64) {
@@ -152,7 +198,10 @@ func (g *Game) Update(dt float64) {
 		g.quit = true
 		return
 	}
+	g.world.Update(dt)
+}
 
+func (g *Game) updateControls(w *ecs.World, dt float64) {
 	if g.fireTimer > 0 {
 		g.fireTimer -= dt
 		if g.fireTimer < 0 {
@@ -167,26 +216,23 @@ func (g *Game) Update(dt float64) {
 		g.spawnBullet()
 		g.fireTimer = fireCooldown
 	}
-
-	g.world.Update(dt)
-	g.clampShip()
-	g.resolveHits()
-	g.updateExplosions(dt)
-	g.cleanupBullets()
-	g.checkNextLevel()
 }
 
 func (g *Game) Draw(r *render.Renderer) {
 	g.world.Draw(r)
 	r.DrawText(0, 0, fmt.Sprintf("Level: %d", g.level), g.levelStyle)
+	if g.status != "" {
+		r.DrawText(0, 1, g.status, g.levelStyle)
+	}
 }
 
 func (g *Game) Resize(w, h int) {
 	g.screenW = w
 	g.screenH = h
+	g.shake.SetViewport(w, h)
 
 	if !g.shipPlaced && g.shipSprite != nil {
-		pos := g.world.Positions[g.ship]
+		pos := g.world.Positions.Get(g.ship)
 		if pos != nil {
 			pos.X = float64((w - g.shipSprite.W) / 2)
 			pos.Y = float64(h - g.shipSprite.H - 1)
@@ -216,7 +262,7 @@ func (g *Game) UpdateActionState(state input.ActionState) {
 }
 
 func (g *Game) applyShipMovement() {
-	vel := g.world.Velocities[g.ship]
+	vel := g.world.Velocities.Get(g.ship)
 	if vel == nil {
 		return
 	}
@@ -241,8 +287,8 @@ func (g *Game) updateEnemyVelocities() {
 	minX := math.MaxFloat64
 	maxX := -math.MaxFloat64
 	for _, e := range g.enemies {
-		pos := g.world.Positions[e]
-		ref := g.world.Sprites[e]
+		pos := g.world.Positions.Get(e)
+		ref := g.world.Sprites.Get(e)
 		if pos == nil || ref == nil || ref.Sprite == nil {
 			continue
 		}
@@ -264,7 +310,7 @@ func (g *Game) updateEnemyVelocities() {
 
 	speed := enemySpeed + float64(g.level-1)*enemySpeedStep
 	for _, e := range g.enemies {
-		vel := g.world.Velocities[e]
+		vel := g.world.Velocities.Get(e)
 		if vel == nil {
 			continue
 		}
@@ -274,17 +320,18 @@ func (g *Game) updateEnemyVelocities() {
 }
 
 func (g *Game) updateEnemyFlyIn() bool {
-	if len(g.enemyTargets) == 0 {
+	if ecs.Register[flyIn](g.world).Len() == 0 {
 		return false
 	}
 	flying := false
 	for _, e := range g.enemies {
-		targetX, ok := g.enemyTargets[e]
-		if !ok {
+		fly := ecs.Get[flyIn](g.world, e)
+		if fly == nil {
 			continue
 		}
-		pos := g.world.Positions[e]
-		vel := g.world.Velocities[e]
+		targetX := fly.targetX
+		pos := g.world.Positions.Get(e)
+		vel := g.world.Velocities.Get(e)
 		if pos == nil || vel == nil {
 			continue
 		}
@@ -292,7 +339,7 @@ func (g *Game) updateEnemyFlyIn() bool {
 		if math.Abs(delta) <= 0.5 {
 			pos.X = targetX
 			vel.DX = 0
-			delete(g.enemyTargets, e)
+			ecs.Remove[flyIn](g.world, e)
 			continue
 		}
 		flying = true
@@ -305,10 +352,10 @@ func (g *Game) updateEnemyFlyIn() bool {
 	}
 	if flying {
 		for _, e := range g.enemies {
-			if _, ok := g.enemyTargets[e]; ok {
+			if ecs.Has[flyIn](g.world, e) {
 				continue
 			}
-			vel := g.world.Velocities[e]
+			vel := g.world.Velocities.Get(e)
 			if vel != nil {
 				vel.DX = 0
 				vel.DY = 0
@@ -319,147 +366,106 @@ func (g *Game) updateEnemyFlyIn() bool {
 }
 
 func (g *Game) spawnBullet() {
-	shipPos := g.world.Positions[g.ship]
+	shipPos := g.world.Positions.Get(g.ship)
 	if shipPos == nil {
 		return
 	}
-	bullet := g.world.NewEntity()
 	bx := shipPos.X + float64(g.shipSprite.W/2)
 	by := shipPos.Y - 1
-	g.world.AddPosition(bullet, bx, by)
-	g.world.AddVelocity(bullet, 0, -bulletSpeed)
-	g.world.AddSprite(bullet, g.bulletSprite, 2)
-	g.bullets = append(g.bullets, bullet)
-}
-
-func (g *Game) cleanupBullets() {
-	if len(g.bullets) == 0 {
+	bullet, err := g.world.Instantiate("bullet", bx, by, nil)
+	if err != nil {
+		g.status = err.Error()
 		return
 	}
-	remaining := g.bullets[:0]
-	for _, b := range g.bullets {
-		pos := g.world.Positions[b]
-		if pos == nil {
-			continue
-		}
-		if pos.Y < -1 {
-			g.removeEntity(b)
-			continue
-		}
-		remaining = append(remaining, b)
-	}
-	g.bullets = remaining
+	ecs.Add(g.world, bullet, projectile{})
 }
 
-func (g *Game) resolveHits() {
-	if len(g.bullets) == 0 || len(g.enemies) == 0 {
+func (g *Game) resolveHits(w *ecs.World, dt float64) {
+	bullets := ecs.Query1[projectile](w).Entities()
+	if len(bullets) == 0 || len(g.enemies) == 0 {
 		return
 	}
-	remainingBullets := g.bullets[:0]
 	remainingEnemies := g.enemies[:0]
 
-	enemyHit := make(map[ecs.Entity]bool, len(g.enemies))
-	for _, b := range g.bullets {
-		bpos := g.world.Positions[b]
+	struck := make(map[ecs.Entity]bool, len(g.enemies))
+	for _, b := range bullets {
+		bpos := g.world.Positions.Get(b)
 		if bpos == nil {
 			continue
 		}
-		bulletRemoved := false
+		// Sweep from the previous step's position so fast bullets can't skip thin enemies.
+		to := collision.Point{X: bpos.X, Y: bpos.Y}
+		from := to
+		if bvel := g.world.Velocities.Get(b); bvel != nil {
+			from = collision.Point{X: bpos.X - bvel.DX*dt, Y: bpos.Y - bvel.DY*dt}
+		}
 		for _, e := range g.enemies {
-			if enemyHit[e] {
+			if struck[e] {
 				continue
 			}
-			epos := g.world.Positions[e]
-			eref := g.world.Sprites[e]
+			epos := g.world.Positions.Get(e)
+			eref := g.world.Sprites.Get(e)
 			if epos == nil || eref == nil || eref.Sprite == nil {
 				continue
 			}
-			if collision.Overlaps(int(math.Floor(bpos.X)), int(math.Floor(bpos.Y)), g.bulletSprite, int(math.Floor(epos.X)), int(math.Floor(epos.Y)), eref.Sprite) {
-				enemyHit[e] = true
-				bulletRemoved = true
+			if _, hit := collision.Sweep(g.bulletSprite, from, to, int(math.Floor(epos.X)), int(math.Floor(epos.Y)), eref.Sprite); hit {
+				struck[e] = true
+				g.world.Destroy(b)
 				break
 			}
 		}
-		if !bulletRemoved {
-			remainingBullets = append(remainingBullets, b)
-		}
 	}
 
 	for _, e := range g.enemies {
-		if enemyHit[e] {
-			hp := g.enemyHP[e] - 1
-			if hp > 0 {
-				g.enemyHP[e] = hp
-				remainingEnemies = append(remainingEnemies, e)
-				continue
+		if struck[e] {
+			if h := ecs.Get[health](g.world, e); h != nil {
+				h.HP--
+				if h.HP > 0 {
+					ecs.Emit(g.world, enemyHit{entity: e})
+					remainingEnemies = append(remainingEnemies, e)
+					continue
+				}
 			}
-			g.onEnemyHit(e)
+			ecs.Emit(g.world, enemyKilled{entity: e})
 			continue
 		}
 		remainingEnemies = append(remainingEnemies, e)
 	}
 	g.enemies = remainingEnemies
-
-	for _, b := range g.bullets {
-		if g.world.Positions[b] == nil {
-			continue
-		}
-		if containsEntity(remainingBullets, b) {
-			continue
-		}
-		g.removeEntity(b)
-	}
-	g.bullets = remainingBullets
 }
 
-func (g *Game) onEnemyHit(e ecs.Entity) {
-	anim := g.enemyAnims[e]
+func (g *Game) explode(e ecs.Entity) {
+	var anim *render.Animation
+	if da := ecs.Get[destroyAnim](g.world, e); da != nil {
+		anim = da.anim
+	}
 	if anim == nil || len(anim.Frames) == 0 {
-		g.removeEntity(e)
+		g.world.Destroy(e)
 		return
 	}
 
 	frames := explosionFrames(anim)
 	if len(frames) == 0 {
-		g.removeEntity(e)
+		g.world.Destroy(e)
 		return
 	}
 
-	ref := g.world.Sprites[e]
+	ref := g.world.Sprites.Get(e)
 	if ref == nil {
 		g.world.AddSprite(e, frames[0], 1)
 	} else {
 		ref.Sprite = frames[0]
 	}
-	if vel := g.world.Velocities[e]; vel != nil {
+	if vel := g.world.Velocities.Get(e); vel != nil {
 		vel.DX = 0
 		vel.DY = 0
 	}
-	g.explosions = append(g.explosions, explosion{
-		entity: e,
-		frames: frames,
-	})
-}
-
-func (g *Game) updateExplosions(dt float64) {
-	if len(g.explosions) == 0 {
-		return
-	}
-	remaining := g.explosions[:0]
-	for _, ex := range g.explosions {
-		ex.elapsed += dt
-		frame := int(ex.elapsed * explodeFPS)
-		if frame >= len(ex.frames) {
-			g.removeEntity(ex.entity)
-			continue
-		}
-		ref := g.world.Sprites[ex.entity]
-		if ref != nil {
-			ref.Sprite = ex.frames[frame]
-		}
-		remaining = append(remaining, ex)
-	}
-	g.explosions = remaining
+	// The stock animation and lifetime systems play the frames once, then
+	// remove the enemy.
+	explosion := &render.Animation{Base: anim.Base, Frames: frames}
+	ecs.Add(g.world, e, ecs.Animated{Player: explosion.Play(explodeFPS)})
+	ecs.Add(g.world, e, ecs.Lifetime{Remaining: float64(len(frames)) / explodeFPS})
+	ecs.Add(g.world, e, exploding{})
 }
 
 func explosionFrames(anim *render.Animation) []*render.Sprite {
@@ -472,29 +478,18 @@ func explosionFrames(anim *render.Animation) []*render.Sprite {
 	return anim.Frames[1:]
 }
 
-func (g *Game) checkNextLevel() {
-	if len(g.enemies) > 0 || len(g.explosions) > 0 {
+func (g *Game) checkNextLevel(w *ecs.World, dt float64) {
+	if !g.enemiesPlaced || len(g.enemies) > 0 || ecs.Register[exploding](w).Len() > 0 {
 		return
 	}
-	g.level++
-	g.enemiesPlaced = false
-	g.layoutEnemies()
-}
-
-func containsEntity(list []ecs.Entity, target ecs.Entity) bool {
-	for _, e := range list {
-		if e == target {
-			return true
-		}
-	}
-	return false
+	ecs.Emit(g.world, levelCleared{level: g.level})
 }
 
-func (g *Game) clampShip() {
+func (g *Game) clampShip(w *ecs.World, dt float64) {
 	if g.screenW <= 0 {
 		return
 	}
-	pos := g.world.Positions[g.ship]
+	pos := g.world.Positions.Get(g.ship)
 	if pos == nil {
 		return
 	}
@@ -513,12 +508,6 @@ func (g *Game) layoutEnemies() {
 	}
 	enemy2Chance := enemy2SpawnChance(g.level)
 	g.enemyDir = 1
-	for e := range g.enemyTargets {
-		delete(g.enemyTargets, e)
-	}
-	for e := range g.enemyHP {
-		delete(g.enemyHP, e)
-	}
 	cols := 8
 	maxCols := (g.screenW + enemyGapX) / (g.enemyMaxW + enemyGapX)
 	if maxCols < 1 {
@@ -537,27 +526,24 @@ func (g *Game) layoutEnemies() {
 		for col := 0; col < cols; col++ {
 			x := startX + col*(g.enemyMaxW+enemyGapX)
 			targetX := float64(x)
-			enemy := g.world.NewEntity()
-			sprite := g.enemySprite
-			anim := g.enemyDestroy
-			hp := 1
+			prefab := "enemy"
 			if g.rng != nil && g.rng.Float64() < enemy2Chance {
-				sprite = g.enemy2Sprite
-				anim = g.enemy2Destroy
-				hp = 3
+				prefab = "enemy2"
 			}
 			flyOffset := float64(g.screenW + g.enemyMaxW)
 			startX := targetX - flyOffset
 			if row%2 == 1 {
 				startX = targetX + flyOffset
 			}
-			g.world.AddPosition(enemy, startX, float64(y))
-			g.world.AddVelocity(enemy, 0, 0)
-			g.world.AddSprite(enemy, sprite, 1)
+			enemy, err := g.world.Instantiate(prefab, startX, float64(y), nil)
+			if err != nil {
+				g.status = err.Error()
+				continue
+			}
 			g.enemies = append(g.enemies, enemy)
-			g.enemyAnims[enemy] = anim
-			g.enemyTargets[enemy] = targetX
-			g.enemyHP[enemy] = hp
+			ecs.Add(g.world, enemy, destroyAnim{anim: g.destroyAnims[prefab]})
+			ecs.Add(g.world, enemy, flyIn{targetX: targetX})
+			ecs.Add(g.world, enemy, health{HP: ecs.Get[ecs.PrefabRef](g.world, enemy).Props.Int("hp", 1)})
 		}
 	}
 	g.enemiesPlaced = true
@@ -574,13 +560,12 @@ func enemy2SpawnChance(level int) float64 {
 	return chance
 }
 
-func (g *Game) removeEntity(e ecs.Entity) {
-	delete(g.world.Positions, e)
-	delete(g.world.Velocities, e)
-	delete(g.world.Sprites, e)
-	delete(g.world.TileMaps, e)
-	delete(g.enemyAnims, e)
-	delete(g.enemyHP, e)
+func mustPrefabSprite(w *ecs.World, name string) *render.Sprite {
+	s, err := w.PrefabSprite(name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return s
 }
 
 func (g *Game) pressed(action input.Action) bool {
@@ -597,6 +582,8 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	// F2 toggles the entity inspector for tuning.
+	eng.AddOverlay("key:f2", ecs.NewInspector(game.world))
 	if err := eng.Run(game); err != nil {
 		log.Fatal(err)
 	}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/dgrundel/glif/assets"
+	"github.com/dgrundel/glif/camera"
 	"github.com/dgrundel/glif/collision"
 	"github.com/dgrundel/glif/engine"
 	"github.com/dgrundel/glif/grid"
@@ -52,6 +53,8 @@ const (
 	treeEdgeBiasMaxBoost     = 1.2
 	screenScorePadRight      = 2
 	screenMessageOffsetY     = 2
+	roughTrauma              = 0.3
+	crashTrauma              = 0.8
 )
 
 type Gate struct {
@@ -106,6 +109,7 @@ type SkiGame struct {
 	showSplash bool
 	gates      []Gate
 	obstacles  []Obstacle
+	shake      *camera.Shake
 	rng        *rand.Rand
 }
 
@@ -138,6 +142,7 @@ func NewSkiGame() *SkiGame {
 		alertStyle: alertStyle,
 		speed:      initialSpeed,
 		targetSpd:  initialSpeed,
+		shake:      camera.NewShake(nil),
 		rng:        rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 	g.playerSpr = g.skiDown
@@ -166,6 +171,7 @@ func (g *SkiGame) Update(dt float64) {
 		g.quit = true
 		return
 	}
+	g.shake.Update(dt)
 	if g.gameOver {
 		if g.actions.Pressed["restart"] || g.actions.Pressed["restart_alt"] {
 			g.reset()
@@ -230,8 +236,9 @@ func (g *SkiGame) Draw(r *render.Renderer) {
 		g.drawSplash(r)
 		return
 	}
-	cameraX := g.playerX - float64(g.screenPX)
-	cameraY := g.playerY - float64(g.screenPY)
+	shakeX, shakeY := g.shake.Offset()
+	cameraX := g.playerX - float64(g.screenPX) - shakeX
+	cameraY := g.playerY - float64(g.screenPY) - shakeY
 
 	type drawItem struct {
 		x      float64
@@ -257,8 +264,8 @@ func (g *SkiGame) Draw(r *render.Renderer) {
 		r.DrawSprite(sx, sy, it.sprite)
 	}
 
-	px := g.screenPX
-	py := g.screenPY
+	px := g.screenPX + int(math.Round(shakeX))
+	py := g.screenPY + int(math.Round(shakeY))
 	r.DrawSprite(px, py, g.playerSpr)
 
 	scoreText := fmt.Sprintf("Score: %d", g.score)
@@ -466,10 +473,12 @@ func (g *SkiGame) checkObstacles() {
 		if obs.Kind == ObstacleTree {
 			g.gameOver = true
 			g.gameOverReason = "Hit a tree"
+			g.shake.AddTrauma(crashTrauma)
 			return
 		}
 		if obs.Kind == ObstacleRough {
 			obs.Hit = true
+			g.shake.AddTrauma(roughTrauma)
 			g.speedPen = maxFloat(g.speedPen, roughSpeedPenalty)
 		}
 		if obs.Kind == ObstacleSnow {
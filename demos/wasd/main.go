@@ -72,7 +72,7 @@ func (g *Game) Draw(r *render.Renderer) {
 func (g *Game) Resize(w, h int) {
 	g.screenW = w
 	g.screenH = h
-	pos := g.world.Positions[g.player]
+	pos := g.world.Positions.Get(g.player)
 	if pos != nil && pos.X == 0 && pos.Y == 0 {
 		pos.X = float64((w / 2) - 1)
 		pos.Y = float64(h / 2)
@@ -80,7 +80,7 @@ func (g *Game) Resize(w, h int) {
 }
 
 func (g *Game) setVelocity(dx, dy float64) {
-	vel := g.world.Velocities[g.player]
+	vel := g.world.Velocities.Get(g.player)
 	if vel == nil {
 		return
 	}
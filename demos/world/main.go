@@ -1,10 +1,14 @@
 package main
 
 import (
+	"bytes"
+	"fmt"
 	"log"
+	"math"
 
 	"github.com/dgrundel/glif/assets"
 	"github.com/dgrundel/glif/camera"
+	"github.com/dgrundel/glif/collision"
 	"github.com/dgrundel/glif/ecs"
 	"github.com/dgrundel/glif/engine"
 	"github.com/dgrundel/glif/grid"
@@ -17,12 +21,20 @@ import (
 type Demo struct {
 	world   *ecs.World
 	cam     *camera.Basic
+	follow  *camera.Follow
 	tile    *tilemap.Map
 	binds   input.ActionMap
 	actions input.ActionState
 	player  ecs.Entity
 	quit    bool
 	bg      grid.Style
+	border  grid.Style
+	marker  grid.Style
+	minimap *render.Viewport
+	// quickSave holds a world snapshot taken with the quick_save action.
+	quickSave []byte
+	// status reports the last quick save or load in the HUD.
+	status string
 }
 
 func NewDemo() *Demo {
@@ -34,40 +46,50 @@ func NewDemo() *Demo {
 	if err != nil {
 		log.Fatal(err)
 	}
+	border := pal.MustStyle('w')
+	marker := pal.MustStyle('g')
 	world := ecs.NewWorld()
 	cam := camera.NewBasic()
 	world.Camera = cam
 
 	duck := assets.MustLoadSprite("demos/world/assets/duck")
 
-	player := world.NewEntity()
-	world.AddPosition(player, 0, 0)
-	world.AddVelocity(player, 0, 0)
-	world.AddSprite(player, duck, 0)
+	var player ecs.Entity
+	world.RegisterFactory("duck", func(w *ecs.World, x, y float64, s tilemap.Spawn) ecs.Entity {
+		e := w.NewEntity()
+		w.AddPosition(e, x, y)
+		w.AddVelocity(e, 0, 0)
+		w.AddSprite(e, duck, 0)
+		player = e
+		return e
+	})
 
-	tm, err := tilemap.LoadFromFiles(
+	tm, _, err := world.LoadLevel(
 		"demos/world/assets/world.map",
 		"demos/world/assets/world.tiles",
+		"demos/world/assets/world.entities",
+		-1,
 	)
 	if err != nil {
 		log.Fatal(err)
 	}
-	tmEntity := world.NewEntity()
-	world.AddPosition(tmEntity, 0, 0)
-	world.AddTileMap(tmEntity, tm, -1)
+
+	bounds := tm.WorldBounds()
+	follow := camera.NewFollow(cam, world.CameraTarget(player))
+	follow.DeadzoneW = 16
+	follow.DeadzoneH = 6
+	follow.LookAhead = 0.4
+	follow.Bounds = &bounds
 
 	world.OnResize = func(w, h int) {
 		cam.SetViewport(w, h)
-		pos := world.Positions[player]
-		if pos != nil && pos.X == 0 && pos.Y == 0 {
-			pos.X = float64((w / 2) - 1)
-			pos.Y = float64(h / 2)
-		}
+		follow.Snap()
 	}
 
 	return &Demo{
 		world:  world,
 		cam:    cam,
+		follow: follow,
 		tile:   tm,
 		player: player,
 		binds: input.ActionMap{
@@ -76,54 +98,128 @@ func NewDemo() *Demo {
 			"move_left":  "a",
 			"move_right": "d",
 			"stop":       " ",
-			"pan_up":     "key:up",
-			"pan_down":   "key:down",
-			"pan_left":   "key:left",
-			"pan_right":  "key:right",
+			"zoom_in":    "=",
+			"zoom_out":   "-",
+			"quick_save": "k",
+			"quick_load": "l",
 			"quit":       "key:esc",
 			"quit_alt":   "key:ctrl+c",
 		},
-		bg: bg,
+		bg:      bg,
+		border:  border,
+		marker:  marker,
+		minimap: render.NewViewport(0, 0, 0, 0, camera.NewBasic()),
 	}
 }
 
 func (d *Demo) Update(dt float64) {
 	d.applyMovement()
-	d.updateCamera(dt)
+	var prevX, prevY float64
+	if pos := d.world.Positions.Get(d.player); pos != nil {
+		prevX, prevY = pos.X, pos.Y
+	}
 	d.world.Update(dt)
+	d.tile.Update(dt)
+	d.resolveTiles(prevX, prevY)
+	d.updateZoom()
+	d.updateQuickSave()
+	d.follow.Update(dt)
 	if d.actions.Pressed["quit"] || d.actions.Pressed["quit_alt"] {
 		d.quit = true
 	}
 }
 
-func (d *Demo) updateCamera(dt float64) {
-	speed := 12.0
-	dx := 0.0
-	dy := 0.0
-	if d.actions.Held["pan_left"] {
-		dx -= 1
+// resolveTiles undoes movement into solid tiles one axis at a time so the
+// player slides along island edges instead of sticking to them.
+func (d *Demo) resolveTiles(prevX, prevY float64) {
+	pos := d.world.Positions.Get(d.player)
+	ref := d.world.Sprites.Get(d.player)
+	if pos == nil || ref == nil || d.tile == nil {
+		return
 	}
-	if d.actions.Held["pan_right"] {
-		dx += 1
+	blocked := func(x, y float64) bool {
+		return collision.Blocked(d.tile, 0, 0, int(math.Floor(x)), int(math.Floor(y)), ref.Sprite)
 	}
-	if d.actions.Held["pan_up"] {
-		dy -= 1
+	if blocked(prevX, prevY) {
+		return
 	}
-	if d.actions.Held["pan_down"] {
-		dy += 1
+	if blocked(pos.X, prevY) {
+		pos.X = prevX
+	}
+	if blocked(pos.X, pos.Y) {
+		pos.Y = prevY
+	}
+}
+
+// updateZoom steps the camera zoom between 1/4 and 2.
+func (d *Demo) updateZoom() {
+	z := d.cam.Zoom()
+	if d.actions.Pressed["zoom_in"] && z < 2 {
+		d.cam.SetZoom(z * 2)
+	}
+	if d.actions.Pressed["zoom_out"] && z > 0.25 {
+		d.cam.SetZoom(z / 2)
+	}
+}
+
+// updateQuickSave snapshots the world into memory or restores the last
+// snapshot. Entity handles survive Load, so d.player stays valid.
+func (d *Demo) updateQuickSave() {
+	if d.actions.Pressed["quick_save"] {
+		var buf bytes.Buffer
+		if err := d.world.Save(&buf); err != nil {
+			d.status = fmt.Sprintf("quick save: %v", err)
+			return
+		}
+		d.quickSave = buf.Bytes()
+		d.status = "saved"
 	}
-	d.cam.Pan(dx, dy, speed, dt)
-	if d.tile != nil {
-		d.cam.ClampTo(d.tile.WorldBounds())
+	if d.actions.Pressed["quick_load"] && d.quickSave != nil {
+		if err := d.world.Load(bytes.NewReader(d.quickSave)); err != nil {
+			d.status = fmt.Sprintf("quick load: %v", err)
+			return
+		}
+		d.follow.Snap()
+		d.status = "loaded"
 	}
 }
 
 func (d *Demo) Draw(r *render.Renderer) {
+	r.Sampling = render.SampleMajority
 	d.world.Draw(r)
+	d.drawMinimap(r)
+	if d.status != "" {
+		r.Screen().DrawText(0, 0, d.status, d.marker)
+	}
+}
+
+// drawMinimap shows the map at one cell per tile in the top-right corner,
+// scrolled to keep the player's tile in view.
+func (d *Demo) drawMinimap(r *render.Renderer) {
+	v := d.minimap
+	if v.W <= 0 || v.H <= 0 {
+		return
+	}
+	r.Rect(v.X-1, v.Y-1, v.W+2, v.H+2, d.border)
+	mr := r.Viewport(v)
+	mr.Clear()
+	cam := v.Camera.(*camera.Basic)
+	tx, ty := 0, 0
+	if pos := d.world.Positions.Get(d.player); pos != nil {
+		tx = int(math.Floor(pos.X)) / d.tile.TileW
+		ty = int(math.Floor(pos.Y)) / d.tile.TileH
+	}
+	cam.SetCenter(float64(tx), float64(ty))
+	cam.ClampTo(camera.Bounds{W: float64(d.tile.W), H: float64(d.tile.H)})
+	d.tile.DrawMinimap(mr, 0, 0)
+	mr.DrawCell(tx, ty, grid.Cell{Ch: '@', Style: d.marker})
 }
 
 func (d *Demo) Resize(w, h int) {
 	d.world.Resize(w, h)
+	mw := min(d.tile.W, w/3)
+	mh := min(d.tile.H, h/3)
+	d.minimap.SetRect(w-mw-1, 1, mw, mh)
 }
 
 func (d *Demo) ActionMap() input.ActionMap {
@@ -167,7 +263,7 @@ func (d *Demo) applyMovement() {
 		dx *= scale
 		dy *= scale
 	}
-	vel := d.world.Velocities[d.player]
+	vel := d.world.Velocities.Get(d.player)
 	if vel != nil {
 		vel.DX = dx * speed
 		vel.DY = dy * speed
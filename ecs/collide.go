@@ -0,0 +1,93 @@
+package ecs
+
+import (
+	"math"
+	"slices"
+
+	"github.com/dgrundel/glif/collision"
+)
+
+// Collider makes an entity collide using its sprite's collision mask. The
+// "collision" system checks every pair of colliders with a Position and a
+// SpriteRef and emits CollisionBegin, CollisionStay and CollisionEnd events.
+type Collider struct {
+	Filter collision.Filter
+}
+
+// CollisionBegin is emitted on the first tick two colliders overlap. A is
+// always the entity with the lower index.
+type CollisionBegin struct {
+	A, B Entity
+}
+
+// CollisionStay is emitted on each later tick the pair still overlaps.
+type CollisionStay struct {
+	A, B Entity
+}
+
+// CollisionEnd is emitted once a pair stops overlapping, including when
+// either entity was destroyed or lost its Collider.
+type CollisionEnd struct {
+	A, B Entity
+}
+
+type contact [2]Entity
+
+// Collisions tests every pair of colliders with collision.OverlapsFiltered
+// at their world positions and emits contact events. NewWorld registers it
+// as the "collision" system.
+func Collisions(w *World, dt float64) {
+	type body struct {
+		e      Entity
+		x, y   int
+		sprite *SpriteRef
+		filter collision.Filter
+	}
+	var bodies []body
+	Query2[Collider, SpriteRef](w, With[Position]()).Each(func(e Entity, c *Collider, s *SpriteRef) {
+		if s.Sprite == nil || s.Sprite.Collision == nil {
+			return
+		}
+		x, y, _ := w.WorldPosition(e)
+		bodies = append(bodies, body{e: e, x: int(math.Floor(x)), y: int(math.Floor(y)), sprite: s, filter: c.Filter})
+	})
+
+	touching := map[contact]bool{}
+	for i := range bodies {
+		a := &bodies[i]
+		for j := i + 1; j < len(bodies); j++ {
+			b := &bodies[j]
+			if !collision.OverlapsFiltered(a.x, a.y, a.sprite.Sprite, a.filter, b.x, b.y, b.sprite.Sprite, b.filter) {
+				continue
+			}
+			pair := contact{a.e, b.e}
+			touching[pair] = true
+			if w.contacts[pair] {
+				Emit(w, CollisionStay{A: a.e, B: b.e})
+			} else {
+				Emit(w, CollisionBegin{A: a.e, B: b.e})
+			}
+		}
+	}
+	// Ended contacts are emitted in entity order so runs are deterministic.
+	var ended []contact
+	for pair := range w.contacts {
+		if !touching[pair] {
+			ended = append(ended, pair)
+		}
+	}
+	sortContacts(ended)
+	for _, pair := range ended {
+		Emit(w, CollisionEnd{A: pair[0], B: pair[1]})
+	}
+	w.contacts = touching
+}
+
+func sortContacts(pairs []contact) {
+	slices.SortFunc(pairs, func(a, b contact) int {
+		if c := compareEntities(a[0], b[0]); c != 0 {
+			return c
+		}
+		return compareEntities(a[1], b[1])
+	})
+}
@@ -0,0 +1,687 @@
+package ecs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dgrundel/glif/camera"
+	"github.com/dgrundel/glif/collision"
+	"github.com/dgrundel/glif/grid"
+	"github.com/dgrundel/glif/input"
+	"github.com/dgrundel/glif/render"
+	"github.com/dgrundel/glif/tilemap"
+	"github.com/gdamore/tcell/v3"
+)
+
+type health struct{ HP int }
+
+// TestStores verifies typed stores add, get, swap-remove and destroy across types.
+func TestStores(t *testing.T) {
+	w := NewWorld()
+	a, b, c := w.NewEntity(), w.NewEntity(), w.NewEntity()
+	for i, e := range []Entity{a, b, c} {
+		w.AddPosition(e, float64(i), 0)
+		Add(w, e, health{HP: i + 1})
+	}
+	if h := Get[health](w, b); h == nil || h.HP != 2 {
+		t.Fatalf("health(b)=%v", h)
+	}
+	Get[health](w, b).HP = 9
+	if Get[health](w, b).HP != 9 {
+		t.Fatalf("Get should return a pointer into the store")
+	}
+
+	if !Remove[health](w, a) || Has[health](w, a) {
+		t.Fatalf("remove failed")
+	}
+	got := Register[health](w).Entities()
+	if len(got) != 2 || got[0] != c || got[1] != b {
+		t.Fatalf("entities after swap-remove=%v want [%v %v]", got, c, b)
+	}
+	if h := Get[health](w, c); h == nil || h.HP != 3 {
+		t.Fatalf("moved value=%v", h)
+	}
+
+	w.Destroy(c)
+	if w.Positions.Has(c) || Has[health](w, c) {
+		t.Fatalf("destroy should remove every component")
+	}
+	if w.Positions.Len() != 2 || Register[health](w).Len() != 1 {
+		t.Fatalf("lens=%d,%d", w.Positions.Len(), Register[health](w).Len())
+	}
+	if Get[Velocity](w, a) != nil {
+		t.Fatalf("missing component should be nil")
+	}
+}
+
+type frozen struct{}
+
+// TestQuery verifies queries match all components, apply filters and iterate in index order.
+func TestQuery(t *testing.T) {
+	w := NewWorld()
+	var es []Entity
+	for i := 0; i < 5; i++ {
+		e := w.NewEntity()
+		es = append(es, e)
+		w.AddPosition(e, 0, 0)
+	}
+	// Add velocities out of order so storage order differs from entity order.
+	for _, i := range []int{4, 1, 3, 0} {
+		w.AddVelocity(es[i], 1, 0)
+	}
+	Add(w, es[3], frozen{})
+
+	var got []Entity
+	Query2[Position, Velocity](w, Without[frozen]()).Each(func(e Entity, p *Position, v *Velocity) {
+		got = append(got, e)
+		p.X += v.DX
+	})
+	want := []Entity{es[0], es[1], es[4]}
+	if len(got) != len(want) {
+		t.Fatalf("got=%v want=%v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got=%v want=%v", got, want)
+		}
+	}
+	if w.Positions.Get(es[1]).X != 1 || w.Positions.Get(es[3]).X != 0 {
+		t.Fatalf("Each should update matched components only")
+	}
+	if n := len(Query1[Position](w, With[frozen]()).Entities()); n != 1 {
+		t.Fatalf("With matched %d entities want 1", n)
+	}
+
+	// A recycled index keeps its place even though its generation is higher.
+	w.Destroy(es[0])
+	r := w.NewEntity()
+	w.AddPosition(r, 0, 0)
+	if got := Query1[Position](w).Entities(); got[0] != r || got[1] != es[1] {
+		t.Fatalf("recycled entity should sort by index: %v", got)
+	}
+}
+
+// TestEntityLifecycle verifies deferred destroy, id recycling and stale handle detection.
+func TestEntityLifecycle(t *testing.T) {
+	w := NewWorld()
+	a := w.NewEntity()
+	b := w.NewEntity()
+	w.AddPosition(a, 1, 1)
+	w.AddPosition(b, 2, 2)
+
+	w.AddSystem(func(w *World, dt float64) {
+		w.Destroy(a)
+		w.Destroy(a)
+		if !w.Positions.Has(a) {
+			t.Fatalf("destroy during update should be deferred")
+		}
+	})
+	w.Update(0)
+	if w.Alive(a) || w.Positions.Has(a) {
+		t.Fatalf("a should be destroyed after Update")
+	}
+
+	c := w.NewEntity()
+	if c.Index() != a.Index() || c.Generation() != a.Generation()+1 {
+		t.Fatalf("c=%d/%d should reuse a's index with the next generation", c.Index(), c.Generation())
+	}
+	w.AddPosition(a, 9, 9) // stale handle
+	if w.Positions.Has(c) || w.Positions.Get(a) != nil {
+		t.Fatalf("stale handle should not add components")
+	}
+	w.AddPosition(c, 3, 3)
+	if w.Positions.Set(a, Position{X: 9}) != nil || w.Positions.Get(c) == nil || w.Positions.Get(c).X != 3 {
+		t.Fatalf("stale Set should not take over c's component")
+	}
+	w.Destroy(a) // stale handle
+	if !w.Alive(c) || w.Positions.Get(c).X != 3 {
+		t.Fatalf("stale destroy should not affect c")
+	}
+	w.Destroy(b)
+	if w.Alive(b) || w.Positions.Len() != 1 {
+		t.Fatalf("destroy outside update should be immediate")
+	}
+}
+
+// TestSystems verifies phase order, before/after constraints, enable/disable, fixed steps and the Render phase.
+func TestSystems(t *testing.T) {
+	w := NewWorld()
+	var log []string
+	add := func(sys System) {
+		t.Helper()
+		name := sys.Name
+		sys.Run = func(w *World, dt float64) { log = append(log, name) }
+		if err := w.Systems.Add(sys); err != nil {
+			t.Fatalf("add %s: %v", name, err)
+		}
+	}
+	add(System{Name: "late", Phase: PostUpdate})
+	add(System{Name: "ai", Phase: Update, After: []string{"input"}})
+	add(System{Name: "input", Phase: Update, Before: []string{"movement"}})
+	add(System{Name: "early", Phase: PreUpdate})
+
+	if got := strings.Join(w.Systems.Order(Update), ","); got != "input,movement,lifetime,ai" {
+		t.Fatalf("update order=%s", got)
+	}
+	w.Update(0.1)
+	if got := strings.Join(log, ","); got != "early,input,ai,late" {
+		t.Fatalf("run order=%s", got)
+	}
+
+	err := w.Systems.Add(System{Name: "loop", Phase: Update, Run: func(*World, float64) {}, Before: []string{"input"}, After: []string{"ai"}})
+	if err == nil || w.Systems.Enabled("loop") {
+		t.Fatalf("cycle should be rejected, err=%v", err)
+	}
+	if err := w.AddSystem(nil); err == nil {
+		t.Fatalf("AddSystem(nil) should fail")
+	}
+
+	log = nil
+	w.Systems.SetEnabled("ai", false)
+	if !w.Systems.Remove("movement") {
+		t.Fatalf("movement should be removable")
+	}
+	e := w.NewEntity()
+	w.AddPosition(e, 0, 0)
+	w.AddVelocity(e, 1, 0)
+	w.Update(0.1)
+	if got := strings.Join(log, ","); got != "early,input,late" {
+		t.Fatalf("run order=%s", got)
+	}
+	if w.Positions.Get(e).X != 0 {
+		t.Fatalf("movement should be removed")
+	}
+
+	w = NewWorld()
+	w.FixedStep = 0.1
+	ticks := 0
+	w.Systems.Add(System{Name: "tick", Fixed: true, Run: func(w *World, dt float64) {
+		if dt != 0.1 {
+			t.Fatalf("fixed dt=%v", dt)
+		}
+		ticks++
+	}})
+	frames := 0
+	w.AddSystem(func(w *World, dt float64) { frames++ })
+	w.Update(0.25)
+	w.Update(0.05)
+	w.Update(0.01)
+	if ticks != 3 || frames != 3 {
+		t.Fatalf("ticks=%d frames=%d want 3,3", ticks, frames)
+	}
+
+	// Render systems run only from Render, with the time since the last one.
+	var renders []float64
+	w.Systems.Add(System{Name: "hud", Phase: Render, Run: func(w *World, dt float64) { renders = append(renders, dt) }})
+	r := render.NewRenderer(grid.NewFrame(4, 4, grid.Cell{Ch: ' '}))
+	w.Render()
+	renders = nil
+	w.Update(0.1)
+	w.Draw(r)
+	w.Draw(r)
+	w.Render()
+	if len(renders) != 1 || math.Abs(renders[0]-0.1) > 1e-9 {
+		t.Fatalf("renders=%v want [0.1]", renders)
+	}
+}
+
+// TestEvents verifies emit-order delivery within Update and per-update clearing.
+func TestEvents(t *testing.T) {
+	type hit struct{ e Entity }
+	type cleared struct{ level int }
+
+	w := NewWorld()
+	var log []string
+	Subscribe(w, func(w *World, ev hit) {
+		log = append(log, fmt.Sprintf("hit %d", ev.e))
+		if ev.e == 2 {
+			Emit(w, cleared{level: 1})
+		}
+	})
+	Subscribe(w, func(w *World, ev cleared) {
+		log = append(log, fmt.Sprintf("cleared %d", ev.level))
+	})
+	w.AddSystem(func(w *World, dt float64) {
+		Emit(w, hit{e: 1})
+		Emit(w, cleared{level: 0})
+		Emit(w, hit{e: 2})
+	})
+
+	w.Update(0.1)
+	if got := strings.Join(log, ","); got != "hit 1,cleared 0,hit 2,cleared 1" {
+		t.Fatalf("delivery=%s", got)
+	}
+	if got := Events[hit](w); len(got) != 2 || got[1].e != 2 {
+		t.Fatalf("hit events=%v", got)
+	}
+	if got := Events[cleared](w); len(got) != 2 {
+		t.Fatalf("cleared events=%v", got)
+	}
+
+	w.Systems.Remove("system-1")
+	w.Update(0.1)
+	if len(Events[hit](w)) != 0 || len(Events[cleared](w)) != 0 {
+		t.Fatalf("events should clear each update")
+	}
+
+	log = nil
+	Emit(w, hit{e: 5})
+	w.DispatchEvents()
+	if got := strings.Join(log, ","); got != "hit 5" {
+		t.Fatalf("manual dispatch=%s", got)
+	}
+}
+
+// TestHierarchy verifies world position resolution, cycle rejection, the children index and cascade destroy.
+func TestHierarchy(t *testing.T) {
+	w := NewWorld()
+	ship := w.NewEntity()
+	w.AddPosition(ship, 10, 5)
+	w.AddVelocity(ship, 2, 0)
+	turret := w.NewEntity()
+	w.AddPosition(turret, 1, -1)
+	barrel := w.NewEntity()
+	w.AddPosition(barrel, 0, -1)
+	if err := w.SetParent(turret, ship); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.SetParent(barrel, turret); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.SetParent(ship, barrel); err == nil {
+		t.Fatalf("cycle should be rejected")
+	}
+
+	w.Update(1)
+	if x, y, ok := w.WorldPosition(barrel); !ok || x != 13 || y != 3 {
+		t.Fatalf("barrel world pos=%v,%v,%v want 13,3", x, y, ok)
+	}
+	if got := w.Children(ship); len(got) != 1 || got[0] != turret {
+		t.Fatalf("children=%v", got)
+	}
+	// Moving and detaching children keeps the index in step.
+	if err := w.SetParent(barrel, ship); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Children(ship); len(got) != 2 || got[0] != turret || got[1] != barrel || len(w.Children(turret)) != 0 {
+		t.Fatalf("after reparent children=%v turret=%v", got, w.Children(turret))
+	}
+	Remove[Parent](w, barrel)
+	if got := w.Children(ship); len(got) != 1 {
+		t.Fatalf("after detach children=%v", got)
+	}
+	w.SetParent(barrel, turret)
+
+	w.AddSystem(func(w *World, dt float64) { w.Destroy(ship) })
+	w.Update(0)
+	if w.Alive(ship) || w.Alive(turret) || w.Alive(barrel) {
+		t.Fatalf("destroying a parent should destroy its descendants")
+	}
+}
+
+// TestPrefabs verifies prefab loading, built-in fields, custom fields and overrides.
+func TestPrefabs(t *testing.T) {
+	w := NewWorld()
+	if err := w.LoadPrefabs(filepath.Join("testdata", "units.prefabs")); err != nil {
+		t.Fatal(err)
+	}
+	crate, err := w.Instantiate("crate", 3, 4, tilemap.Props{"hp": "5", "z": "7"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pos := w.Positions.Get(crate); pos == nil || pos.X != 3 || pos.Y != 4 {
+		t.Fatalf("pos=%v", pos)
+	}
+	spr := w.Sprites.Get(crate)
+	if spr == nil || spr.Sprite == nil || spr.Z != 7 {
+		t.Fatalf("sprite=%v", spr)
+	}
+	if w.Velocities.Has(crate) {
+		t.Fatalf("crate should have no velocity")
+	}
+	ref := Get[PrefabRef](w, crate)
+	if ref == nil || ref.Name != "crate" || ref.Props.Int("hp", 0) != 5 || ref.Props.Has("sprite") {
+		t.Fatalf("ref=%v", ref)
+	}
+	if c := Get[Collider](w, crate); c == nil || c.Filter.Category != 2 || c.Filter.Mask != collision.LayerAll {
+		t.Fatalf("crate collider=%v", c)
+	}
+
+	bullet, err := w.Instantiate("bullet", 0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Sprites.Get(bullet).Sprite != spr.Sprite {
+		t.Fatalf("prefabs should share loaded sprites")
+	}
+	if v := w.Velocities.Get(bullet); v == nil || v.DY != -10 {
+		t.Fatalf("bullet velocity=%v", v)
+	}
+	if c := Get[Collider](w, bullet); c == nil || c.Filter != (collision.Filter{Category: 4, Mask: 2, Kinds: "h"}) {
+		t.Fatalf("bullet collider=%v", c)
+	}
+
+	if _, err := w.Instantiate("crate", 0, 0, tilemap.Props{"z": "high"}); err == nil {
+		t.Fatalf("invalid override should fail")
+	}
+	if _, err := w.Instantiate("ghost", 0, 0, nil); err == nil {
+		t.Fatalf("unknown prefab should fail")
+	}
+}
+
+// TestSaveLoad verifies a snapshot round-trips entities, components, sprites and the camera, and
+// that Load rejects inconsistent free lists and resets per-world state.
+func TestSaveLoad(t *testing.T) {
+	newGameWorld := func() *World {
+		w := NewWorld()
+		w.Camera = camera.NewShake(camera.NewBasic())
+		RegisterCodec(w, JSONCodec[health]())
+		return w
+	}
+	w := newGameWorld()
+	if err := w.LoadPrefabs(filepath.Join("testdata", "units.prefabs")); err != nil {
+		t.Fatal(err)
+	}
+	crate, err := w.Instantiate("crate", 3, 4, tilemap.Props{"hp": "5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	Add(w, crate, health{HP: 2})
+	lid := w.NewEntity()
+	w.AddPosition(lid, 0, -1)
+	w.SetParent(lid, crate)
+	Add(w, lid, struct{ unsaved int }{1})
+	gone := w.NewEntity()
+	w.Destroy(gone)
+	basicCamera(w.Camera).Set(7, 8)
+
+	var buf bytes.Buffer
+	if err := w.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := newGameWorld()
+	if err := loaded.Load(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Alive(gone) || !loaded.Alive(crate) || !loaded.Alive(lid) {
+		t.Fatalf("entity handles should survive a round trip")
+	}
+	if h := Get[health](loaded, crate); h == nil || h.HP != 2 {
+		t.Fatalf("health=%v", h)
+	}
+	if x, y, _ := loaded.WorldPosition(lid); x != 3 || y != 3 {
+		t.Fatalf("lid world pos=%v,%v", x, y)
+	}
+	spr := loaded.Sprites.Get(crate)
+	if spr == nil || spr.Sprite == nil || spr.Z != 2 || spr.Sprite.Source != w.Sprites.Get(crate).Sprite.Source {
+		t.Fatalf("sprite=%v", spr)
+	}
+	if ref := Get[PrefabRef](loaded, crate); ref == nil || ref.Props.Int("hp", 0) != 5 {
+		t.Fatalf("prefab ref=%v", ref)
+	}
+	if x, y := basicCamera(loaded.Camera).Position(); x != 7 || y != 8 {
+		t.Fatalf("camera=%v,%v", x, y)
+	}
+	if next := loaded.NewEntity(); next.Index() != gone.Index() || next == gone {
+		t.Fatalf("free list should be restored, got %d", next)
+	}
+
+	if err := NewWorld().Load(bytes.NewReader(buf.Bytes())); err == nil {
+		t.Fatalf("loading without the health codec should fail")
+	}
+	if err := loaded.Load(strings.NewReader(`{"version": 99}`)); err == nil || !loaded.Alive(crate) {
+		t.Fatalf("unsupported version should fail and keep the world, err=%v", err)
+	}
+
+	// A free list naming a live entity would let NewEntity reuse it.
+	var snap map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &snap); err != nil {
+		t.Fatal(err)
+	}
+	snap["free"] = []int{crate.Index()}
+	bad, _ := json.Marshal(snap)
+	if err := loaded.Load(bytes.NewReader(bad)); err == nil || !loaded.Alive(lid) {
+		t.Fatalf("a live free index should fail and keep the world, err=%v", err)
+	}
+
+	loaded.FixedStep = 1
+	loaded.Update(0.5)
+	loaded.contacts = map[contact]bool{{crate, lid}: true}
+	if err := loaded.Load(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.accumulator != 0 || len(loaded.contacts) != 0 {
+		t.Fatalf("Load should reset accumulator=%v contacts=%v", loaded.accumulator, loaded.contacts)
+	}
+}
+
+// TestSaveLoadTileMaps verifies level maps reload into a fresh world, saved
+// tiles wait for a clean decode and sprites without a source are skipped.
+func TestSaveLoadTileMaps(t *testing.T) {
+	path := func(name string) string { return filepath.Join("testdata", name) }
+	m, spawns, err := tilemap.LoadLevel(path("arena.map"), path("arena.tiles"), path("arena.entities"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spawns) != 1 {
+		t.Fatalf("spawns=%v", spawns)
+	}
+	wall, floor := m.At(0, 0), m.At(1, 1)
+	m.Set(1, 1, wall)
+
+	w := NewWorld()
+	level := w.NewEntity()
+	w.AddTileMap(level, m, 0)
+	drawn := w.NewEntity()
+	w.AddPosition(drawn, 1, 2)
+	w.AddSprite(drawn, &render.Sprite{W: 1, H: 1, Cells: make([]grid.Cell, 1)}, 1)
+
+	var buf bytes.Buffer
+	if err := w.Save(&buf); err != nil {
+		t.Fatalf("a sprite without a source should not fail Save: %v", err)
+	}
+
+	fresh := NewWorld()
+	if err := fresh.Load(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	ref := fresh.TileMaps.Get(level)
+	if ref == nil || ref.Map == m || ref.Map.EntitiesPath != path("arena.entities") || ref.Map.At(1, 1) != wall {
+		t.Fatalf("tile map should reload with LoadLevel and keep its tiles, ref=%v", ref)
+	}
+	if fresh.Sprites.Has(drawn) || !fresh.Positions.Has(drawn) {
+		t.Fatalf("only the sourceless sprite should be left out")
+	}
+
+	// Break the last entity so decoding fails after the map has decoded.
+	var snap map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &snap); err != nil {
+		t.Fatal(err)
+	}
+	entities := snap["entities"].([]any)
+	entities[len(entities)-1].(map[string]any)["components"].(map[string]any)["ecs.Position"] = "oops"
+	bad, _ := json.Marshal(snap)
+	m.Set(1, 1, floor)
+	if err := w.Load(bytes.NewReader(bad)); err == nil {
+		t.Fatalf("a bad component should fail Load")
+	}
+	if m.At(1, 1) != floor {
+		t.Fatalf("a failed Load should not change a map in use")
+	}
+}
+
+// TestStockSystems verifies lifetime, offscreen culling, animation and collision events.
+func TestStockSystems(t *testing.T) {
+	w := NewWorld()
+	cam := camera.NewBasic()
+	cam.SetViewport(10, 5)
+	w.Camera = cam
+	if err := w.LoadPrefabs(filepath.Join("testdata", "units.prefabs")); err != nil {
+		t.Fatal(err)
+	}
+	crate, _ := w.PrefabSprite("crate")
+
+	brief := w.NewEntity()
+	Add(w, brief, Lifetime{Remaining: 0.15})
+	far := w.NewEntity()
+	w.AddPosition(far, 20, 0)
+	Add(w, far, OffscreenCull{Margin: 2})
+	near := w.NewEntity()
+	w.AddPosition(near, 11, 0)
+	Add(w, near, OffscreenCull{Margin: 2})
+
+	frames := []*render.Sprite{crate, crate.Restyle(func(s grid.Style) grid.Style { return s })}
+	anim := w.NewEntity()
+	w.AddSprite(anim, frames[0], 0)
+	Add(w, anim, Animated{Player: (&render.Animation{Base: crate, Frames: frames}).Play(10)})
+
+	a, _ := w.Instantiate("crate", 0, 0, nil)
+	b, _ := w.Instantiate("crate", 1, 0, nil)
+	var log []string
+	Subscribe(w, func(w *World, ev CollisionBegin) { log = append(log, fmt.Sprintf("begin %d %d", ev.A, ev.B)) })
+	Subscribe(w, func(w *World, ev CollisionStay) { log = append(log, "stay") })
+	Subscribe(w, func(w *World, ev CollisionEnd) { log = append(log, fmt.Sprintf("end %d %d", ev.A, ev.B)) })
+
+	w.Update(0.1)
+	if !w.Alive(brief) || w.Alive(far) || !w.Alive(near) {
+		t.Fatalf("alive brief=%v far=%v near=%v", w.Alive(brief), w.Alive(far), w.Alive(near))
+	}
+	if w.Sprites.Get(anim).Sprite != frames[1] {
+		t.Fatalf("animation should advance to frame 1")
+	}
+	w.Update(0.1)
+	if w.Alive(brief) {
+		t.Fatalf("lifetime should expire")
+	}
+	w.Positions.Get(b).X = 5
+	w.Update(0.1)
+	want := fmt.Sprintf("begin %d %d,stay,end %d %d", a, b, a, b)
+	if got := strings.Join(log, ","); got != want {
+		t.Fatalf("collision events=%s want %s", got, want)
+	}
+}
+
+// TestInspector verifies selection, live field edits, read-only handles, pausing and drawing.
+func TestInspector(t *testing.T) {
+	type stats struct {
+		HP   int
+		Rate float64
+		name string
+	}
+	w := NewWorld()
+	if err := w.LoadPrefabs(filepath.Join("testdata", "units.prefabs")); err != nil {
+		t.Fatal(err)
+	}
+	a, _ := w.Instantiate("crate", 2, 1, nil)
+	Add(w, a, stats{HP: 3, Rate: 0.5, name: "a"})
+	b := w.NewEntity()
+	w.AddPosition(b, 9, 9)
+
+	in := NewInspector(w)
+	press := func(keys ...input.Key) {
+		pressed := map[input.Key]bool{}
+		for _, k := range keys {
+			pressed[k] = true
+		}
+		in.HandleInput(input.State{Pressed: pressed})
+	}
+	if e, ok := in.Selected(); !ok || e != a {
+		t.Fatalf("selected=%d want first entity %d", e, a)
+	}
+	press("]")
+	if e, _ := in.Selected(); e != b {
+		t.Fatalf("] selected %d want %d", e, b)
+	}
+	press("]")
+	if e, _ := in.Selected(); e != a {
+		t.Fatalf("] should wrap to %d, got %d", a, e)
+	}
+
+	press("key:right") // Position.X
+	if x := w.Positions.Get(a).X; x != 3 {
+		t.Fatalf("X=%v want 3", x)
+	}
+	rows := in.fields(a)
+	hp := slices.IndexFunc(rows, func(r inspectorRow) bool { return r.label == "HP" })
+	for range hp {
+		press("key:down")
+	}
+	press("key:enter")
+	in.HandleInput(input.State{Typed: []rune("17")})
+	press("key:enter")
+	if got := Get[stats](w, a).HP; got != 17 {
+		t.Fatalf("HP=%d want 17", got)
+	}
+	press("key:down")
+	press("key:right")
+	if s := Get[stats](w, a); s.Rate != 1.5 || s.name != "a" {
+		t.Fatalf("stats=%+v", *s)
+	}
+	press("p")
+	if !in.Paused() {
+		t.Fatalf("p should pause")
+	}
+
+	frame := grid.NewFrame(60, 20, grid.Cell{Ch: ' '})
+	r := render.NewRenderer(frame)
+	w.Draw(r)
+	in.Draw(r)
+	if cell := frame.At(3, 1); cell.Ch != '#' || cell.Style.Bg != inspectorMask.Bg {
+		t.Fatalf("mask cell=%+v", cell)
+	}
+	if cell := frame.At(2, 0); cell.Ch != tcell.RuneULCorner {
+		t.Fatalf("bounds corner=%q", cell.Ch)
+	}
+	panel := func() string {
+		var sb strings.Builder
+		frame.ClearAll()
+		in.Draw(r)
+		for y := 0; y < frame.H; y++ {
+			for x := frame.W - in.Width; x < frame.W; x++ {
+				sb.WriteRune(frame.At(x, y).Ch)
+			}
+		}
+		return sb.String()
+	}
+	// The field rows scroll to keep the selected field on screen.
+	if got := panel(); !strings.Contains(got, "> Rate: 1.5") || strings.Contains(got, "ecs.Position") {
+		t.Fatalf("panel should scroll to Rate, panel=%q", got)
+	}
+	for range len(rows) {
+		press("key:up")
+	}
+	if got := panel(); !strings.Contains(got, "ecs.Position") || !strings.Contains(got, "> X: 3") {
+		t.Fatalf("panel should scroll back to X, panel=%q", got)
+	}
+
+	// Entity handles are shown but can't be edited.
+	press("]")
+	if err := w.SetParent(b, a); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		press("key:down")
+	}
+	press("key:right")
+	press("key:enter")
+	if p := Get[Parent](w, b); p.Entity != a || in.editing || in.message == "" {
+		t.Fatalf("Parent.Entity should be read-only, parent=%d editing=%v", p.Entity, in.editing)
+	}
+	if got := panel(); !strings.Contains(got, "> Entity: 0 gen 0") {
+		t.Fatalf("panel=%q", got)
+	}
+	Remove[Parent](w, b)
+	press("[")
+
+	w.Destroy(a)
+	if e, _ := in.Selected(); e != b {
+		t.Fatalf("destroyed selection should move to %d, got %d", b, e)
+	}
+}
@@ -0,0 +1,96 @@
+package ecs
+
+import "reflect"
+
+// eventQueue holds one event type's subscribers, undelivered events, and
+// events delivered since the last Update began.
+type eventQueue[T any] struct {
+	handlers  []func(w *World, ev T)
+	pending   []T
+	delivered []T
+}
+
+// eventChannel is the type-erased view World uses to dispatch and clear queues.
+type eventChannel interface {
+	deliverNext(w *World)
+	clearDelivered()
+}
+
+func (q *eventQueue[T]) deliverNext(w *World) {
+	ev := q.pending[0]
+	var zero T
+	q.pending[0] = zero
+	q.pending = q.pending[1:]
+	if len(q.pending) == 0 {
+		q.pending = nil
+	}
+	q.delivered = append(q.delivered, ev)
+	for _, h := range q.handlers {
+		h(w, ev)
+	}
+}
+
+func (q *eventQueue[T]) clearDelivered() {
+	clear(q.delivered)
+	q.delivered = q.delivered[:0]
+}
+
+func eventsOf[T any](w *World) *eventQueue[T] {
+	t := reflect.TypeFor[T]()
+	if q, ok := w.queues[t]; ok {
+		return q.(*eventQueue[T])
+	}
+	q := &eventQueue[T]{}
+	if w.queues == nil {
+		w.queues = map[reflect.Type]eventChannel{}
+	}
+	w.queues[t] = q
+	w.queueOrder = append(w.queueOrder, q)
+	return q
+}
+
+// Subscribe calls fn for every T event when events are dispatched. Any type
+// can be an event; subscribers run in the order they subscribed.
+func Subscribe[T any](w *World, fn func(w *World, ev T)) {
+	q := eventsOf[T](w)
+	q.handlers = append(q.handlers, fn)
+}
+
+// Emit queues ev for the next dispatch. Update dispatches after each phase,
+// so a system's events reach subscribers before the next phase runs.
+func Emit[T any](w *World, ev T) {
+	q := eventsOf[T](w)
+	q.pending = append(q.pending, ev)
+	w.emitted = append(w.emitted, q)
+}
+
+// Events returns the T events dispatched since the current or latest Update
+// began, in emit order, for code that polls instead of subscribing (e.g. a
+// Render system or Draw). The slice is cleared when the next Update starts.
+func Events[T any](w *World) []T {
+	q, ok := w.queues[reflect.TypeFor[T]()]
+	if !ok {
+		return nil
+	}
+	return q.(*eventQueue[T]).delivered
+}
+
+// DispatchEvents delivers queued events to subscribers in emit order,
+// including events emitted by subscribers along the way. Update calls it
+// after each phase; call it yourself to deliver events emitted outside Update.
+func (w *World) DispatchEvents() {
+	w.lock()
+	defer w.unlock()
+	for len(w.emitted) > 0 {
+		q := w.emitted[0]
+		w.emitted = w.emitted[1:]
+		q.deliverNext(w)
+	}
+	w.emitted = nil
+}
+
+func (w *World) clearEvents() {
+	for _, q := range w.queueOrder {
+		q.clearDelivered()
+	}
+}
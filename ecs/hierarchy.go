@@ -0,0 +1,91 @@
+package ecs
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Parent attaches an entity to another. A child's Position is local: its
+// world position is its Position plus its parent's world position, so
+// children move with their parents. Destroying a parent destroys its
+// children. Change a parent with SetParent or Add, not by writing Entity
+// through a pointer, so Children stays in step.
+type Parent struct {
+	Entity Entity
+}
+
+// indexChildren keeps w.children in step with the Parent store.
+func (w *World) indexChildren() {
+	s := Register[Parent](w)
+	s.onSet = func(e Entity, p *Parent) {
+		if w.children == nil {
+			w.children = map[Entity][]Entity{}
+		}
+		w.children[p.Entity] = append(w.children[p.Entity], e)
+	}
+	s.onRemove = func(e Entity, p *Parent) {
+		kids := w.children[p.Entity]
+		if i := slices.Index(kids, e); i >= 0 {
+			kids = slices.Delete(kids, i, i+1)
+		}
+		if len(kids) == 0 {
+			delete(w.children, p.Entity)
+		} else {
+			w.children[p.Entity] = kids
+		}
+	}
+}
+
+// SetParent attaches child to parent, keeping child's Position as its local
+// offset. It fails if either entity is not alive or if parent is child or
+// one of its descendants.
+func (w *World) SetParent(child, parent Entity) error {
+	if !w.Alive(child) || !w.Alive(parent) {
+		return fmt.Errorf("set parent of entity %d to %d: entity not alive", child, parent)
+	}
+	for p, ok := parent, true; ok; p, ok = w.parentOf(p) {
+		if p == child {
+			return fmt.Errorf("set parent of entity %d to %d: would form a cycle", child, parent)
+		}
+	}
+	Add(w, child, Parent{Entity: parent})
+	return nil
+}
+
+// Children returns parent's direct children in ascending index order.
+func (w *World) Children(parent Entity) []Entity {
+	out := slices.Clone(w.children[parent])
+	slices.SortFunc(out, compareEntities)
+	return out
+}
+
+// WorldPosition resolves e's position through its parents. It reports false
+// if e has no Position. Parents without a Position count as the origin.
+func (w *World) WorldPosition(e Entity) (x, y float64, ok bool) {
+	pos := w.Positions.Get(e)
+	if pos == nil {
+		return 0, 0, false
+	}
+	x, y = pos.X, pos.Y
+	// Bound the walk so a cycle made with Add[Parent] can't hang.
+	for p, steps := e, 0; steps < len(w.generations); steps++ {
+		var has bool
+		if p, has = w.parentOf(p); !has {
+			break
+		}
+		if pp := w.Positions.Get(p); pp != nil {
+			x += pp.X
+			y += pp.Y
+		}
+	}
+	return x, y, true
+}
+
+// parentOf returns e's parent if it has a live one.
+func (w *World) parentOf(e Entity) (Entity, bool) {
+	p := Get[Parent](w, e)
+	if p == nil || !w.Alive(p.Entity) {
+		return 0, false
+	}
+	return p.Entity, true
+}
@@ -0,0 +1,504 @@
+package ecs
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dgrundel/glif/grid"
+	"github.com/dgrundel/glif/input"
+	"github.com/dgrundel/glif/render"
+	"github.com/gdamore/tcell/v3"
+)
+
+// Inspector is a debug overlay for a World. It lists live entities with
+// their components, outlines the selected entity and marks its collision
+// mask cells, and edits numeric component fields in place. It implements
+// engine.Overlay and engine.Pauser:
+//
+//	eng.AddOverlay("key:f2", ecs.NewInspector(w))
+//
+// Keys: [ and ] select the previous or next entity, up and down select a
+// field, left and right change it by 1, enter starts typing a new value
+// (enter sets it, esc cancels) and p pauses the game.
+//
+// Only exported number fields can be edited. Entity fields, such as
+// Parent.Entity, and fields tagged `inspect:"readonly"` are shown but not
+// edited, since changing a handle in place breaks what refers to it.
+type Inspector struct {
+	World *World
+	// Width is the panel width in cells; the panel sits at the right edge.
+	Width int
+
+	selected Entity
+	field    int
+	editing  bool
+	entry    []rune
+	paused   bool
+	message  string
+}
+
+// inspectorRow is a component header (no value) or one of its fields.
+// Nested struct fields are flattened, e.g. "Filter.Mask".
+type inspectorRow struct {
+	label    string
+	value    reflect.Value
+	readOnly bool // a handle or a field tagged inspect:"readonly"
+}
+
+// editable reports whether the row's value can be changed from the panel.
+func (row inspectorRow) editable() bool {
+	return !row.readOnly && editable(row.value)
+}
+
+var entityType = reflect.TypeFor[Entity]()
+
+var (
+	inspectorPanel  = grid.Style{Fg: grid.TCellColor(tcell.ColorWhite), Bg: grid.TCellColor(tcell.ColorBlack)}
+	inspectorHeader = grid.Style{Fg: grid.TCellColor(tcell.ColorAqua), Bg: grid.TCellColor(tcell.ColorBlack), Bold: true}
+	inspectorCursor = grid.Style{Fg: grid.TCellColor(tcell.ColorYellow), Bg: grid.TCellColor(tcell.ColorBlack), Bold: true}
+	inspectorDim    = grid.Style{Fg: grid.TCellColor(tcell.ColorGray), Bg: grid.TCellColor(tcell.ColorBlack)}
+	inspectorBounds = grid.Style{Fg: grid.TCellColor(tcell.ColorYellow), Bg: grid.InheritColor()}
+	inspectorMask   = grid.Style{Fg: grid.InheritColor(), Bg: grid.TCellColor(tcell.ColorMaroon)}
+)
+
+// NewInspector returns an inspector for w with a 36-cell panel, selecting
+// the first live entity.
+func NewInspector(w *World) *Inspector {
+	return &Inspector{World: w, Width: 36}
+}
+
+// Selected returns the selected entity. When it has been destroyed the next
+// live entity is selected instead; ok is false when there are none.
+func (in *Inspector) Selected() (Entity, bool) {
+	w := in.World
+	if w.Alive(in.selected) {
+		return in.selected, true
+	}
+	live := w.liveEntities()
+	if len(live) == 0 {
+		return 0, false
+	}
+	next := live[0]
+	for _, e := range live {
+		if e.Index() > in.selected.Index() {
+			next = e
+			break
+		}
+	}
+	in.Select(next)
+	return next, true
+}
+
+// Select selects e and its first field.
+func (in *Inspector) Select(e Entity) {
+	in.selected = e
+	in.field = 0
+	in.editing = false
+	in.message = ""
+}
+
+// Paused reports whether the game should stop updating.
+func (in *Inspector) Paused() bool {
+	return in.paused
+}
+
+// HandleInput applies one frame of keys to the selection and its fields.
+// The engine calls it while the overlay is shown.
+func (in *Inspector) HandleInput(state input.State) {
+	e, ok := in.Selected()
+	if !ok {
+		return
+	}
+	if in.editing {
+		in.handleEntry(e, state)
+		return
+	}
+	if state.Pressed["p"] {
+		in.paused = !in.paused
+	}
+	if state.Pressed["]"] {
+		in.step(e, 1)
+	}
+	if state.Pressed["["] {
+		in.step(e, -1)
+	}
+	fields := in.fields(in.selected)
+	if state.Pressed["key:down"] {
+		in.field++
+	}
+	if state.Pressed["key:up"] {
+		in.field--
+	}
+	in.field = max(0, min(in.field, len(fields)-1))
+	if len(fields) == 0 {
+		return
+	}
+	row := fields[in.field]
+	if state.Pressed["key:right"] {
+		in.adjust(row, 1)
+	}
+	if state.Pressed["key:left"] {
+		in.adjust(row, -1)
+	}
+	if state.Pressed["key:enter"] {
+		if row.editable() {
+			in.editing = true
+			in.entry = nil
+			in.message = ""
+		} else {
+			in.message = "field is not editable"
+		}
+	}
+}
+
+// handleEntry collects a typed value for the selected field.
+func (in *Inspector) handleEntry(e Entity, state input.State) {
+	for _, r := range state.Typed {
+		if unicode.IsPrint(r) {
+			in.entry = append(in.entry, r)
+		}
+	}
+	if state.Pressed["key:backspace"] && len(in.entry) > 0 {
+		in.entry = in.entry[:len(in.entry)-1]
+	}
+	if state.Pressed["key:esc"] {
+		in.editing = false
+		return
+	}
+	if !state.Pressed["key:enter"] {
+		return
+	}
+	in.editing = false
+	fields := in.fields(e)
+	if in.field >= len(fields) || !fields[in.field].editable() {
+		return
+	}
+	if err := setField(fields[in.field].value, string(in.entry)); err != nil {
+		in.message = err.Error()
+	}
+}
+
+// step selects the live entity delta places away, wrapping around.
+func (in *Inspector) step(e Entity, delta int) {
+	live := in.World.liveEntities()
+	for i, other := range live {
+		if other == e {
+			in.Select(live[((i+delta)%len(live)+len(live))%len(live)])
+			return
+		}
+	}
+}
+
+func (in *Inspector) adjust(row inspectorRow, delta int) {
+	if !row.editable() {
+		in.message = "field is not editable"
+		return
+	}
+	switch v := row.value; v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n := v.Int() + int64(delta); !v.OverflowInt(n) {
+			v.SetInt(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		switch n := v.Uint(); {
+		case delta < 0 && n > 0:
+			v.SetUint(n - 1)
+		case delta > 0 && !v.OverflowUint(n+1):
+			v.SetUint(n + 1)
+		}
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(v.Float() + float64(delta))
+	}
+}
+
+// fields returns e's editable and read-only field rows, without headers.
+func (in *Inspector) fields(e Entity) []inspectorRow {
+	var out []inspectorRow
+	for _, row := range in.World.inspectorRows(e) {
+		if row.value.IsValid() {
+			out = append(out, row)
+		}
+	}
+	return out
+}
+
+// inspectorRows lists e's components in store registration order, each
+// header followed by its fields.
+func (w *World) inspectorRows(e Entity) []inspectorRow {
+	var rows []inspectorRow
+	for _, s := range w.storeOrder {
+		v := s.value(e)
+		if !v.IsValid() {
+			continue
+		}
+		rows = append(rows, inspectorRow{label: s.Name()})
+		if v.Kind() != reflect.Struct {
+			rows = append(rows, inspectorRow{label: "value", value: v, readOnly: v.Type() == entityType})
+			continue
+		}
+		rows = appendFieldRows(rows, "", v, false)
+	}
+	return rows
+}
+
+// appendFieldRows flattens v's fields into rows. readOnly is inherited by
+// the fields of a nested struct tagged inspect:"readonly".
+func appendFieldRows(rows []inspectorRow, prefix string, v reflect.Value, readOnly bool) []inspectorRow {
+	for i := 0; i < v.NumField(); i++ {
+		f, fv := v.Type().Field(i), v.Field(i)
+		ro := readOnly || f.Type == entityType || f.Tag.Get("inspect") == "readonly"
+		if fv.Kind() == reflect.Struct && fv.NumField() > 0 {
+			rows = appendFieldRows(rows, prefix+f.Name+".", fv, ro)
+			continue
+		}
+		rows = append(rows, inspectorRow{label: prefix + f.Name, value: fv, readOnly: ro})
+	}
+	return rows
+}
+
+// editable reports whether v is an exported, settable number.
+func editable(v reflect.Value) bool {
+	if !v.IsValid() || !v.CanSet() {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// setField parses text into the numeric field v.
+func setField(v reflect.Value, text string) error {
+	if !editable(v) {
+		return fmt.Errorf("field is not editable")
+	}
+	text = strings.TrimSpace(text)
+	bits := v.Type().Bits()
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 0, bits)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", text)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(text, 0, bits)
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", text)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, bits)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", text)
+		}
+		v.SetFloat(f)
+	}
+	return nil
+}
+
+// formatField shows numbers and strings as values, entities by index and
+// generation, and references by type.
+func formatField(v reflect.Value) string {
+	if v.Type() == entityType {
+		e := Entity(v.Uint())
+		return fmt.Sprintf("%d gen %d", e.Index(), e.Generation())
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		if v.IsNil() {
+			return "nil"
+		}
+		if v.Kind() == reflect.Map || v.Kind() == reflect.Slice {
+			return fmt.Sprint(v)
+		}
+		if v.Kind() == reflect.Interface {
+			return v.Elem().Type().String()
+		}
+		return v.Type().String()
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', 6, v.Type().Bits())
+	case reflect.Struct:
+		return v.Type().String()
+	}
+	return fmt.Sprint(v)
+}
+
+// Draw outlines the selected entity in the world camera's view, marks its
+// collision mask cells and draws the panel.
+func (in *Inspector) Draw(r *render.Renderer) {
+	e, ok := in.Selected()
+	if ok {
+		in.highlight(r, e)
+	}
+	in.drawPanel(r, e, ok)
+}
+
+func (in *Inspector) highlight(r *render.Renderer, e Entity) {
+	w := in.World
+	x, y, ok := w.WorldPosition(e)
+	if !ok {
+		return
+	}
+	cam := w.Camera
+	if rc := r.Camera(); rc != nil {
+		cam = rc
+	}
+	toScreen := func(wx, wy float64) (int, int) {
+		if cam != nil {
+			wx, wy = cam.WorldToScreen(wx, wy)
+		}
+		return int(math.Floor(wx)), int(math.Floor(wy))
+	}
+	sw, sh := 1, 1
+	var mask *render.CollisionMask
+	if s := w.Sprites.Get(e); s != nil && s.Sprite != nil {
+		sw, sh = s.Sprite.W, s.Sprite.H
+		mask = s.Sprite.Collision
+	}
+	screen := r.Screen()
+	x0, y0 := toScreen(x, y)
+	x1, y1 := toScreen(x+float64(sw), y+float64(sh))
+	screen.Rect(x0-1, y0-1, x1-x0+2, y1-y0+2, inspectorBounds)
+	if mask == nil {
+		return
+	}
+	for my := 0; my < mask.H; my++ {
+		for mx := 0; mx < mask.W; mx++ {
+			if !mask.At(mx, my) {
+				continue
+			}
+			sx, sy := toScreen(x+float64(mx), y+float64(my))
+			screen.DrawCell(sx, sy, grid.Cell{Ch: r.Frame.At(sx, sy).Ch, Style: inspectorMask})
+		}
+	}
+}
+
+func (in *Inspector) drawPanel(r *render.Renderer, selected Entity, ok bool) {
+	w := in.World
+	width := min(in.Width, r.Frame.W)
+	if width <= 2 || r.Frame.H <= 0 {
+		return
+	}
+	left := r.Frame.W - width
+	screen := r.Screen()
+	screen.Rect(left, 0, width, r.Frame.H, inspectorPanel, render.RectOptions{Fill: true})
+
+	y := 0
+	line := func(text string, style grid.Style) {
+		if y >= r.Frame.H {
+			return
+		}
+		if runes := []rune(text); len(runes) > width-2 {
+			text = string(runes[:width-2])
+		}
+		screen.DrawText(left+1, y, text, style)
+		y++
+	}
+
+	live := w.liveEntities()
+	title := fmt.Sprintf("Inspector  %d entities", len(live))
+	if in.paused {
+		title += "  PAUSED"
+	}
+	line(title, inspectorHeader)
+	if !ok {
+		line("no entities", inspectorDim)
+		return
+	}
+
+	// A window of the entity list around the selection.
+	const listRows = 7
+	at := 0
+	for i, e := range live {
+		if e == selected {
+			at = i
+		}
+	}
+	first, last := scrollWindow(at, len(live), listRows)
+	for _, e := range live[first:last] {
+		var names []string
+		for _, s := range w.storeOrder {
+			if s.Has(e) {
+				names = append(names, shortName(s.Name()))
+			}
+		}
+		text := fmt.Sprintf("  %d %s", e.Index(), strings.Join(names, " "))
+		style := inspectorPanel
+		if e == selected {
+			text = ">" + text[1:]
+			style = inspectorCursor
+		}
+		line(text, style)
+	}
+	line("", inspectorPanel)
+
+	line(fmt.Sprintf("entity %d  gen %d", selected.Index(), selected.Generation()), inspectorHeader)
+	type panelLine struct {
+		text  string
+		style grid.Style
+	}
+	var rows []panelLine
+	field, at := 0, 0
+	for _, row := range w.inspectorRows(selected) {
+		if !row.value.IsValid() {
+			rows = append(rows, panelLine{row.label, inspectorHeader})
+			continue
+		}
+		value := formatField(row.value)
+		style := inspectorPanel
+		if !row.editable() {
+			style = inspectorDim
+		}
+		cursor := " "
+		if field == in.field {
+			cursor = ">"
+			style = inspectorCursor
+			at = len(rows)
+			if in.editing {
+				value = string(in.entry) + "_"
+			}
+		}
+		rows = append(rows, panelLine{fmt.Sprintf("%s %s: %s", cursor, row.label, value), style})
+		field++
+	}
+	// Scroll the rows around the selected field, leaving room for the
+	// message and help lines.
+	room := r.Frame.H - y - 3
+	if in.message != "" {
+		room--
+	}
+	first, last = scrollWindow(at, len(rows), max(room, 1))
+	for _, l := range rows[first:last] {
+		line(l.text, l.style)
+	}
+
+	if in.message != "" {
+		line(in.message, inspectorCursor)
+	}
+	line("", inspectorPanel)
+	line("[ ] entity  up/down field", inspectorDim)
+	line("left/right ±1  enter set  p pause", inspectorDim)
+}
+
+// scrollWindow returns the bounds of a size-row window over n rows that
+// keeps row at near its middle.
+func scrollWindow(at, n, size int) (first, last int) {
+	first = max(0, min(at-size/2, n-size))
+	return first, min(first+size, n)
+}
+
+// shortName drops the package from a component name, e.g. "ecs.Position".
+func shortName(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 && !strings.Contains(name[:i], "[") {
+		return name[i+1:]
+	}
+	return name
+}
@@ -0,0 +1,234 @@
+package ecs
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dgrundel/glif/assets"
+	"github.com/dgrundel/glif/collision"
+	"github.com/dgrundel/glif/render"
+	"github.com/dgrundel/glif/tilemap"
+)
+
+// Prefab is an entity template loaded from a `.prefabs` file. Props holds
+// every key=value on its line, built-in fields and custom ones alike.
+type Prefab struct {
+	Name  string
+	Props tilemap.Props
+
+	dir string // sprite paths are relative to the prefabs file
+}
+
+// PrefabRef records which prefab an entity came from and its custom fields
+// (props other than the built-in ones), including spawn-time overrides.
+type PrefabRef struct {
+	Name  string
+	Props tilemap.Props
+}
+
+// prefabKeys are the props Instantiate turns into components.
+var prefabKeys = map[string]bool{
+	"sprite": true, "z": true, "vx": true, "vy": true,
+	"layer": true, "mask": true, "kinds": true,
+	"lifetime": true, "cull": true,
+}
+
+// prefabSpec is a prefab's props, with overrides, parsed into components.
+type prefabSpec struct {
+	sprite   string
+	z        int
+	vel      *Velocity
+	collider *Collider
+	lifetime *Lifetime
+	cull     *OffscreenCull
+	fields   tilemap.Props
+}
+
+// LoadPrefabs reads a `.prefabs` file into w.Prefabs, replacing prefabs
+// with the same name. Each line defines one prefab:
+//
+//	# <name> [key=value ...]
+//	enemy sprite=enemy z=1 vx=0 vy=0 layer=2 hp=3
+//
+// sprite is a base path for assets.LoadSprite, relative to the file. z is
+// the draw depth, vx and vy add a Velocity, and layer, mask and kinds add a
+// Collider (layer and mask are bit sets, e.g. 2 or 0x6). lifetime adds a
+// Lifetime in seconds and cull adds an OffscreenCull with that margin. Any
+// other key is a custom field, available from the entity's PrefabRef.
+func (w *World) LoadPrefabs(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	loaded := map[string]*Prefab{}
+	for i, line := range strings.Split(string(data), "\n") {
+		lineNo := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		props := tilemap.Props{}
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok || key == "" {
+				return fmt.Errorf("prefabs line %d: invalid property %q (expected key=value)", lineNo, field)
+			}
+			props[key] = value
+		}
+		p := &Prefab{Name: fields[0], Props: props, dir: dir}
+		if _, err := parsePrefab(p, nil); err != nil {
+			return fmt.Errorf("prefabs line %d: %w", lineNo, err)
+		}
+		loaded[p.Name] = p
+	}
+	if w.Prefabs == nil {
+		w.Prefabs = map[string]*Prefab{}
+	}
+	maps.Copy(w.Prefabs, loaded)
+	return nil
+}
+
+// Instantiate creates an entity from the named prefab at x,y. overrides
+// replace the prefab's props for this entity only, e.g. {"hp": "5"} or
+// {"sprite": "boss"}; it may be nil.
+func (w *World) Instantiate(name string, x, y float64, overrides tilemap.Props) (Entity, error) {
+	p := w.Prefabs[name]
+	if p == nil {
+		return 0, fmt.Errorf("no prefab named %q", name)
+	}
+	spec, err := parsePrefab(p, overrides)
+	if err != nil {
+		return 0, err
+	}
+	var sprite *render.Sprite
+	if spec.sprite != "" {
+		if sprite, err = w.loadSprite(p.resolve(spec.sprite)); err != nil {
+			return 0, fmt.Errorf("prefab %q: %w", name, err)
+		}
+	}
+
+	e := w.NewEntity()
+	w.AddPosition(e, x, y)
+	if sprite != nil {
+		w.AddSprite(e, sprite, spec.z)
+	}
+	if spec.vel != nil {
+		w.Velocities.Set(e, *spec.vel)
+	}
+	if spec.collider != nil {
+		Add(w, e, *spec.collider)
+	}
+	if spec.lifetime != nil {
+		Add(w, e, *spec.lifetime)
+	}
+	if spec.cull != nil {
+		Add(w, e, *spec.cull)
+	}
+	Add(w, e, PrefabRef{Name: name, Props: spec.fields})
+	return e, nil
+}
+
+// PrefabSprite returns the named prefab's sprite, loading it on first use.
+// Entities instantiated from the prefab share it.
+func (w *World) PrefabSprite(name string) (*render.Sprite, error) {
+	p := w.Prefabs[name]
+	if p == nil {
+		return nil, fmt.Errorf("no prefab named %q", name)
+	}
+	base := p.Props.String("sprite", "")
+	if base == "" {
+		return nil, fmt.Errorf("prefab %q has no sprite", name)
+	}
+	return w.loadSprite(p.resolve(base))
+}
+
+func (w *World) loadSprite(path string) (*render.Sprite, error) {
+	if s, ok := w.sprites[path]; ok {
+		return s, nil
+	}
+	s, err := assets.LoadSprite(path)
+	if err != nil {
+		return nil, err
+	}
+	if w.sprites == nil {
+		w.sprites = map[string]*render.Sprite{}
+	}
+	w.sprites[path] = s
+	return s, nil
+}
+
+func (p *Prefab) resolve(base string) string {
+	if filepath.IsAbs(base) {
+		return base
+	}
+	return filepath.Join(p.dir, base)
+}
+
+func parsePrefab(p *Prefab, overrides tilemap.Props) (prefabSpec, error) {
+	props := maps.Clone(p.Props)
+	if props == nil {
+		props = tilemap.Props{}
+	}
+	maps.Copy(props, overrides)
+
+	spec := prefabSpec{sprite: props["sprite"], fields: tilemap.Props{}}
+	for k, v := range props {
+		if !prefabKeys[k] {
+			spec.fields[k] = v
+		}
+	}
+	if v, ok := props["z"]; ok {
+		z, err := strconv.Atoi(v)
+		if err != nil {
+			return spec, fmt.Errorf("prefab %q: invalid z %q", p.Name, v)
+		}
+		spec.z = z
+	}
+	if props.Has("vx") || props.Has("vy") {
+		var vel Velocity
+		for key, dst := range map[string]*float64{"vx": &vel.DX, "vy": &vel.DY} {
+			if v, ok := props[key]; ok {
+				f, err := strconv.ParseFloat(v, 64)
+				if err != nil {
+					return spec, fmt.Errorf("prefab %q: invalid %s %q", p.Name, key, v)
+				}
+				*dst = f
+			}
+		}
+		spec.vel = &vel
+	}
+	if props.Has("layer") || props.Has("mask") || props.Has("kinds") {
+		f := collision.Filter{Category: collision.LayerDefault, Mask: collision.LayerAll, Kinds: props["kinds"]}
+		for key, dst := range map[string]*collision.Layer{"layer": &f.Category, "mask": &f.Mask} {
+			if v, ok := props[key]; ok {
+				n, err := strconv.ParseUint(v, 0, 32)
+				if err != nil {
+					return spec, fmt.Errorf("prefab %q: invalid %s %q", p.Name, key, v)
+				}
+				*dst = collision.Layer(n)
+			}
+		}
+		spec.collider = &Collider{Filter: f}
+	}
+	if v, ok := props["lifetime"]; ok {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return spec, fmt.Errorf("prefab %q: invalid lifetime %q", p.Name, v)
+		}
+		spec.lifetime = &Lifetime{Remaining: f}
+	}
+	if v, ok := props["cull"]; ok {
+		m, err := strconv.Atoi(v)
+		if err != nil {
+			return spec, fmt.Errorf("prefab %q: invalid cull %q", p.Name, v)
+		}
+		spec.cull = &OffscreenCull{Margin: m}
+	}
+	return spec, nil
+}
@@ -0,0 +1,132 @@
+package ecs
+
+import "slices"
+
+// Filter narrows a query to entities that pass it.
+type Filter func(w *World, e Entity) bool
+
+// With keeps entities that have a T component.
+func With[T any]() Filter {
+	return func(w *World, e Entity) bool {
+		return Has[T](w, e)
+	}
+}
+
+// Without keeps entities that have no T component.
+func Without[T any]() Filter {
+	return func(w *World, e Entity) bool {
+		return !Has[T](w, e)
+	}
+}
+
+// View1 iterates entities with an A component, in ascending index order.
+type View1[A any] struct {
+	w       *World
+	a       *Store[A]
+	filters []Filter
+}
+
+// View2 iterates entities with A and B components, in ascending index order.
+type View2[A, B any] struct {
+	w       *World
+	a       *Store[A]
+	b       *Store[B]
+	filters []Filter
+}
+
+// View3 iterates entities with A, B and C components, in ascending index order.
+type View3[A, B, C any] struct {
+	w       *World
+	a       *Store[A]
+	b       *Store[B]
+	c       *Store[C]
+	filters []Filter
+}
+
+func Query1[A any](w *World, filters ...Filter) *View1[A] {
+	return &View1[A]{w: w, a: Register[A](w), filters: filters}
+}
+
+func Query2[A, B any](w *World, filters ...Filter) *View2[A, B] {
+	return &View2[A, B]{w: w, a: Register[A](w), b: Register[B](w), filters: filters}
+}
+
+func Query3[A, B, C any](w *World, filters ...Filter) *View3[A, B, C] {
+	return &View3[A, B, C]{w: w, a: Register[A](w), b: Register[B](w), c: Register[C](w), filters: filters}
+}
+
+// Entities returns the matching entities in ascending index order.
+func (v *View1[A]) Entities() []Entity {
+	return matchEntities(v.w, v.filters, v.a)
+}
+
+// Each calls fn for every match. fn may add and remove components and
+// destroy entities: entities created during iteration are not visited, and
+// entities that stop matching before their turn are skipped.
+func (v *View1[A]) Each(fn func(e Entity, a *A)) {
+	for _, e := range v.Entities() {
+		a := v.a.Get(e)
+		if a == nil {
+			continue
+		}
+		fn(e, a)
+	}
+}
+
+func (v *View2[A, B]) Entities() []Entity {
+	return matchEntities(v.w, v.filters, v.a, v.b)
+}
+
+// Each calls fn for every match; see View1.Each.
+func (v *View2[A, B]) Each(fn func(e Entity, a *A, b *B)) {
+	for _, e := range v.Entities() {
+		a, b := v.a.Get(e), v.b.Get(e)
+		if a == nil || b == nil {
+			continue
+		}
+		fn(e, a, b)
+	}
+}
+
+func (v *View3[A, B, C]) Entities() []Entity {
+	return matchEntities(v.w, v.filters, v.a, v.b, v.c)
+}
+
+// Each calls fn for every match; see View1.Each.
+func (v *View3[A, B, C]) Each(fn func(e Entity, a *A, b *B, c *C)) {
+	for _, e := range v.Entities() {
+		a, b, c := v.a.Get(e), v.b.Get(e), v.c.Get(e)
+		if a == nil || b == nil || c == nil {
+			continue
+		}
+		fn(e, a, b, c)
+	}
+}
+
+// matchEntities scans the smallest store and keeps entities present in all
+// stores and accepted by every filter, sorted by index.
+func matchEntities(w *World, filters []Filter, stores ...componentStore) []Entity {
+	smallest := stores[0]
+	for _, s := range stores[1:] {
+		if len(s.Entities()) < len(smallest.Entities()) {
+			smallest = s
+		}
+	}
+	out := make([]Entity, 0, len(smallest.Entities()))
+next:
+	for _, e := range smallest.Entities() {
+		for _, s := range stores {
+			if s != smallest && !s.Has(e) {
+				continue next
+			}
+		}
+		for _, f := range filters {
+			if !f(w, e) {
+				continue next
+			}
+		}
+		out = append(out, e)
+	}
+	slices.SortFunc(out, compareEntities)
+	return out
+}
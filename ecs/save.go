@@ -0,0 +1,374 @@
+package ecs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/dgrundel/glif/camera"
+	"github.com/dgrundel/glif/tilemap"
+)
+
+// SnapshotVersion is the snapshot format written by Save. Load rejects
+// other versions.
+const SnapshotVersion = 1
+
+// Codec saves and loads one component type. Encode returns a value for
+// encoding/json, or nil to leave the component out of the snapshot; Decode
+// builds the component from what Encode produced.
+type Codec[T any] struct {
+	Encode func(w *World, v *T) (any, error)
+	Decode func(w *World, data json.RawMessage) (T, error)
+}
+
+// JSONCodec saves a component with encoding/json, so only its exported
+// fields are kept.
+func JSONCodec[T any]() Codec[T] {
+	return Codec[T]{
+		Encode: func(w *World, v *T) (any, error) {
+			return v, nil
+		},
+		Decode: func(w *World, data json.RawMessage) (T, error) {
+			var v T
+			err := json.Unmarshal(data, &v)
+			return v, err
+		},
+	}
+}
+
+// RegisterCodec makes Save include T components, replacing any codec set
+// for T before. Components are keyed by their store's Name in snapshots.
+func RegisterCodec[T any](w *World, c Codec[T]) {
+	if w.codecs == nil {
+		w.codecs = map[reflect.Type]componentCodec{}
+	}
+	w.codecs[reflect.TypeFor[T]()] = &codec[T]{Codec: c, store: Register[T](w)}
+}
+
+// componentCodec is the type-erased view Save and Load use.
+type componentCodec interface {
+	name() string
+	encode(w *World, e Entity) (json.RawMessage, bool, error)
+	// decode returns a func that adds the decoded component to an entity,
+	// so a snapshot can be fully decoded before the world is changed.
+	decode(w *World, data json.RawMessage) (func(e Entity), error)
+}
+
+type codec[T any] struct {
+	Codec[T]
+	store *Store[T]
+}
+
+func (c *codec[T]) name() string {
+	return c.store.Name()
+}
+
+func (c *codec[T]) encode(w *World, e Entity) (json.RawMessage, bool, error) {
+	v := c.store.Get(e)
+	if v == nil {
+		return nil, false, nil
+	}
+	out, err := c.Encode(w, v)
+	if err != nil || out == nil {
+		return nil, false, err
+	}
+	data, err := json.Marshal(out)
+	return data, true, err
+}
+
+func (c *codec[T]) decode(w *World, data json.RawMessage) (func(e Entity), error) {
+	v, err := c.Decode(w, data)
+	if err != nil {
+		return nil, err
+	}
+	return func(e Entity) { c.store.Set(e, v) }, nil
+}
+
+type snapshot struct {
+	Version     int           `json:"version"`
+	Generations []uint32      `json:"generations"`
+	Free        []int         `json:"free"`
+	Entities    []savedEntity `json:"entities"`
+	Camera      *savedCamera  `json:"camera,omitempty"`
+}
+
+type savedEntity struct {
+	ID         Entity                     `json:"id"`
+	Components map[string]json.RawMessage `json:"components"`
+}
+
+type savedCamera struct {
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+	Zoom float64 `json:"zoom"`
+}
+
+// Save writes a JSON snapshot of the world: every live entity, each of its
+// components that has a codec, and the camera position. Entity handles are
+// kept as they are, so handles held by the game stay valid after Load.
+func (w *World) Save(out io.Writer) error {
+	snap := snapshot{
+		Version:     SnapshotVersion,
+		Generations: w.generations,
+		Free:        w.free,
+		Entities:    []savedEntity{},
+	}
+	codecs := w.codecList()
+	for _, e := range w.liveEntities() {
+		saved := savedEntity{ID: e, Components: map[string]json.RawMessage{}}
+		for _, c := range codecs {
+			data, ok, err := c.encode(w, e)
+			if err != nil {
+				return fmt.Errorf("save entity %d %s: %w", e, c.name(), err)
+			}
+			if ok {
+				saved.Components[c.name()] = data
+			}
+		}
+		snap.Entities = append(snap.Entities, saved)
+	}
+	if cam := basicCamera(w.Camera); cam != nil {
+		x, y := cam.Position()
+		snap.Camera = &savedCamera{X: x, Y: y, Zoom: cam.Zoom()}
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "\t")
+	return enc.Encode(snap)
+}
+
+// Load replaces the world's entities with a snapshot written by Save.
+// Systems, prefabs, factories and event subscribers are kept. The snapshot
+// is decoded completely first, so on error the world's entities are left
+// alone. Tile maps are reloaded from their files the way they were first
+// loaded, with LoadLevel when they had an entities file, unless the world
+// already uses them; their saved tiles are applied once decoding succeeds.
+// Load cannot be called during Update.
+func (w *World) Load(in io.Reader) error {
+	if w.updating > 0 {
+		return fmt.Errorf("load world: cannot load during Update")
+	}
+	var snap snapshot
+	if err := json.NewDecoder(in).Decode(&snap); err != nil {
+		return fmt.Errorf("load world: %w", err)
+	}
+	if snap.Version != SnapshotVersion {
+		return fmt.Errorf("load world: unsupported snapshot version %d (want %d)", snap.Version, SnapshotVersion)
+	}
+	codecs := w.codecList()
+	known := map[string]bool{}
+	for _, c := range codecs {
+		known[c.name()] = true
+	}
+
+	// Tile maps already in the world are reused rather than reloaded.
+	w.loadingMaps = map[[3]string]*loadingMap{}
+	defer func() { w.loadingMaps = nil }()
+	w.TileMaps.Each(func(e Entity, ref *TileMapRef) {
+		if ref.Map != nil && ref.Map.MapPath != "" {
+			w.loadingMaps[mapKey(ref.Map)] = &loadingMap{m: ref.Map}
+		}
+	})
+
+	type pending struct {
+		e   Entity
+		add []func(e Entity)
+	}
+	entities := make([]pending, 0, len(snap.Entities))
+	for _, saved := range snap.Entities {
+		i := saved.ID.Index()
+		if i >= len(snap.Generations) || snap.Generations[i] != saved.ID.Generation() {
+			return fmt.Errorf("load world: entity %d does not match its generation", saved.ID)
+		}
+		p := pending{e: saved.ID}
+		for name := range saved.Components {
+			if !known[name] {
+				return fmt.Errorf("load world: no codec registered for component %q", name)
+			}
+		}
+		for _, c := range codecs {
+			data, ok := saved.Components[c.name()]
+			if !ok {
+				continue
+			}
+			add, err := c.decode(w, data)
+			if err != nil {
+				return fmt.Errorf("load entity %d %s: %w", saved.ID, c.name(), err)
+			}
+			p.add = append(p.add, add)
+		}
+		entities = append(entities, p)
+	}
+
+	live := make([]bool, len(snap.Generations))
+	for _, p := range entities {
+		live[p.e.Index()] = true
+	}
+	for _, i := range snap.Free {
+		if i < 0 || i >= len(live) || live[i] {
+			return fmt.Errorf("load world: free index %d is not a destroyed entity", i)
+		}
+	}
+
+	for _, s := range w.storeOrder {
+		for _, e := range append([]Entity(nil), s.Entities()...) {
+			s.Remove(e)
+		}
+	}
+	w.generations = append([]uint32(nil), snap.Generations...)
+	w.alive = make([]bool, len(w.generations))
+	w.free = append([]int(nil), snap.Free...)
+	w.dying, w.dyingSet = nil, nil
+	// Contacts and the fixed-step remainder belong to the old world.
+	w.contacts = nil
+	w.accumulator = 0
+	for _, p := range entities {
+		w.alive[p.e.Index()] = true
+		for _, add := range p.add {
+			add(p.e)
+		}
+	}
+	for _, lm := range w.loadingMaps {
+		for i, id := range lm.cells {
+			lm.m.Set(i%lm.m.W, i/lm.m.W, id)
+		}
+	}
+	if cam := basicCamera(w.Camera); cam != nil && snap.Camera != nil {
+		cam.SetZoom(snap.Camera.Zoom)
+		cam.Set(snap.Camera.X, snap.Camera.Y)
+	}
+	return nil
+}
+
+// loadingMap is a tile map used by a snapshot during Load, and the saved
+// tiles to apply to it once the whole snapshot has decoded.
+type loadingMap struct {
+	m     *tilemap.Map
+	cells []int
+}
+
+// mapKey identifies a tile map by the files it was loaded from.
+func mapKey(m *tilemap.Map) [3]string {
+	return [3]string{m.MapPath, m.TilesPath, m.EntitiesPath}
+}
+
+// codecList returns the world's codecs in store registration order, so
+// snapshots are written the same way every time.
+func (w *World) codecList() []componentCodec {
+	var out []componentCodec
+	for _, s := range w.storeOrder {
+		for _, c := range w.codecs {
+			if c.name() == s.Name() {
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
+
+// basicCamera finds the Basic camera that holds the view position, looking
+// through a Shake.
+func basicCamera(c camera.Camera) *camera.Basic {
+	switch c := c.(type) {
+	case *camera.Basic:
+		return c
+	case *camera.Shake:
+		return basicCamera(c.Camera)
+	}
+	return nil
+}
+
+func registerBuiltinCodecs(w *World) {
+	RegisterCodec(w, JSONCodec[Position]())
+	RegisterCodec(w, JSONCodec[Velocity]())
+	RegisterCodec(w, JSONCodec[Parent]())
+	RegisterCodec(w, JSONCodec[PrefabRef]())
+	RegisterCodec(w, JSONCodec[Collider]())
+	RegisterCodec(w, JSONCodec[Lifetime]())
+	RegisterCodec(w, JSONCodec[OffscreenCull]())
+
+	type savedSprite struct {
+		Source string `json:"source,omitempty"`
+		Z      int    `json:"z"`
+	}
+	RegisterCodec(w, Codec[SpriteRef]{
+		Encode: func(w *World, v *SpriteRef) (any, error) {
+			if v.Sprite != nil && v.Sprite.Source == "" {
+				// Built in code, so there is no file to reload it from.
+				return nil, nil
+			}
+			s := savedSprite{Z: v.Z}
+			if v.Sprite != nil {
+				s.Source = v.Sprite.Source
+			}
+			return s, nil
+		},
+		Decode: func(w *World, data json.RawMessage) (SpriteRef, error) {
+			var s savedSprite
+			if err := json.Unmarshal(data, &s); err != nil {
+				return SpriteRef{}, err
+			}
+			ref := SpriteRef{Z: s.Z}
+			if s.Source != "" {
+				sprite, err := w.loadSprite(s.Source)
+				if err != nil {
+					return SpriteRef{}, err
+				}
+				ref.Sprite = sprite
+			}
+			return ref, nil
+		},
+	})
+
+	type savedTileMap struct {
+		Map      string `json:"map"`
+		Tiles    string `json:"tiles"`
+		Entities string `json:"entities,omitempty"`
+		Z        int    `json:"z"`
+		Cells    []int  `json:"cells"`
+	}
+	RegisterCodec(w, Codec[TileMapRef]{
+		Encode: func(w *World, v *TileMapRef) (any, error) {
+			if v.Map == nil || v.Map.MapPath == "" {
+				return nil, fmt.Errorf("tile map was not loaded from files")
+			}
+			return savedTileMap{
+				Map:      v.Map.MapPath,
+				Tiles:    v.Map.TilesPath,
+				Entities: v.Map.EntitiesPath,
+				Z:        v.Z,
+				Cells:    v.Map.Tiles,
+			}, nil
+		},
+		Decode: func(w *World, data json.RawMessage) (TileMapRef, error) {
+			var s savedTileMap
+			if err := json.Unmarshal(data, &s); err != nil {
+				return TileMapRef{}, err
+			}
+			key := [3]string{s.Map, s.Tiles, s.Entities}
+			lm := w.loadingMaps[key]
+			if lm == nil {
+				var m *tilemap.Map
+				var err error
+				if s.Entities != "" {
+					// Spawns are entities already in the snapshot.
+					m, _, err = tilemap.LoadLevel(s.Map, s.Tiles, s.Entities)
+				} else {
+					m, err = tilemap.LoadFromFiles(s.Map, s.Tiles)
+				}
+				if err != nil {
+					return TileMapRef{}, err
+				}
+				lm = &loadingMap{m: m}
+				if w.loadingMaps != nil {
+					w.loadingMaps[key] = lm
+				}
+			}
+			if len(s.Cells) != len(lm.m.Tiles) {
+				return TileMapRef{}, fmt.Errorf("saved tiles do not match map %s size", s.Map)
+			}
+			lm.cells = s.Cells
+			return TileMapRef{Map: lm.m, Z: s.Z}, nil
+		},
+	})
+}
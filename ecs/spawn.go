@@ -0,0 +1,61 @@
+package ecs
+
+import (
+	"fmt"
+
+	"github.com/dgrundel/glif/tilemap"
+)
+
+// Factory creates an entity for a map spawn at world position x,y.
+type Factory func(w *World, x, y float64, s tilemap.Spawn) Entity
+
+// RegisterFactory sets the factory used for spawns of the given type.
+func (w *World) RegisterFactory(typ string, f Factory) {
+	if w.Factories == nil {
+		w.Factories = make(map[string]Factory)
+	}
+	w.Factories[typ] = f
+}
+
+// Spawn creates entities for spawns, offsetting their positions by the
+// map's world origin. Types without a factory are instantiated from the
+// prefab of the same name, with the spawn's props as overrides. It fails on
+// the first spawn with neither, before creating anything.
+func (w *World) Spawn(spawns []tilemap.Spawn, originX, originY float64) ([]Entity, error) {
+	for _, s := range spawns {
+		if w.Factories[s.Type] == nil && w.Prefabs[s.Type] == nil {
+			return nil, fmt.Errorf("no factory or prefab registered for %q", s.Type)
+		}
+	}
+	out := make([]Entity, 0, len(spawns))
+	for _, s := range spawns {
+		x, y := originX+s.X, originY+s.Y
+		if f := w.Factories[s.Type]; f != nil {
+			out = append(out, f(w, x, y, s))
+			continue
+		}
+		e, err := w.Instantiate(s.Type, x, y, s.Props)
+		if err != nil {
+			return out, err
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// LoadLevel loads a map with tilemap.LoadLevel, adds it as a tile map entity
+// at the origin with depth z, and spawns its entities.
+func (w *World) LoadLevel(mapPath, tilesPath, entitiesPath string, z int) (*tilemap.Map, []Entity, error) {
+	m, spawns, err := tilemap.LoadLevel(mapPath, tilesPath, entitiesPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	entities, err := w.Spawn(spawns, 0, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	e := w.NewEntity()
+	w.AddPosition(e, 0, 0)
+	w.AddTileMap(e, m, z)
+	return m, entities, nil
+}
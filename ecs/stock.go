@@ -0,0 +1,71 @@
+package ecs
+
+import "github.com/dgrundel/glif/render"
+
+// Animated drives an entity's SpriteRef from an animation player. The
+// "animation" system advances Player each update and shows its frame.
+type Animated struct {
+	Player *render.AnimationPlayer
+}
+
+// Lifetime destroys an entity once Remaining seconds have passed.
+type Lifetime struct {
+	Remaining float64
+}
+
+// OffscreenCull destroys an entity once its sprite (or its position, without
+// one) is more than Margin cells outside the world camera's view.
+type OffscreenCull struct {
+	Margin int
+}
+
+// Animate advances Animated players and copies their frame into SpriteRef.
+// NewWorld registers it as the "animation" system.
+func Animate(w *World, dt float64) {
+	Query2[Animated, SpriteRef](w).Each(func(e Entity, a *Animated, s *SpriteRef) {
+		if a.Player == nil {
+			return
+		}
+		a.Player.Update(dt)
+		if frame := a.Player.Sprite(); frame != nil {
+			s.Sprite = frame
+		}
+	})
+}
+
+// ExpireLifetimes counts Lifetimes down and destroys expired entities.
+// NewWorld registers it as the "lifetime" system.
+func ExpireLifetimes(w *World, dt float64) {
+	Query1[Lifetime](w).Each(func(e Entity, l *Lifetime) {
+		l.Remaining -= dt
+		if l.Remaining <= 0 {
+			w.Destroy(e)
+		}
+	})
+}
+
+// CullOffscreen destroys OffscreenCull entities outside w.Camera's view.
+// It does nothing until the camera has a viewport. NewWorld registers it as
+// the "cull" system.
+func CullOffscreen(w *World, dt float64) {
+	cam := w.Camera
+	if cam == nil {
+		return
+	}
+	if v, ok := cam.(interface{ Viewport() (int, int) }); ok {
+		if vw, vh := v.Viewport(); vw <= 0 || vh <= 0 {
+			return
+		}
+	}
+	Query2[OffscreenCull, Position](w).Each(func(e Entity, c *OffscreenCull, _ *Position) {
+		x, y, _ := w.WorldPosition(e)
+		sw, sh := 1, 1
+		if s := w.Sprites.Get(e); s != nil && s.Sprite != nil {
+			sw, sh = s.Sprite.W, s.Sprite.H
+		}
+		m := c.Margin
+		if !cam.Visible(x-float64(m), y-float64(m), sw+2*m, sh+2*m) {
+			w.Destroy(e)
+		}
+	})
+}
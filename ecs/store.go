@@ -0,0 +1,195 @@
+package ecs
+
+import "reflect"
+
+// Store holds one component type for many entities. Values are packed
+// densely in insertion order (removals swap the last value into the gap),
+// so ranging over Entities or Each touches contiguous memory.
+//
+// Pointers returned by Get, Set and Each point into the packed slice and are
+// only valid until the next Set of a new entity or Remove on the same store.
+type Store[T any] struct {
+	name     string
+	sparse   []int // entity index -> dense index + 1; 0 means absent
+	entities []Entity
+	values   []T
+
+	// onSet and onRemove, when non-nil, see each value after it is stored
+	// and before it is replaced or removed, so World can index it.
+	onSet, onRemove func(e Entity, v *T)
+}
+
+// componentStore is the type-erased view World uses to manage stores.
+type componentStore interface {
+	Name() string
+	Has(e Entity) bool
+	Remove(e Entity) bool
+	Entities() []Entity
+	// value returns e's component as an addressable value, or an invalid
+	// Value if e has none.
+	value(e Entity) reflect.Value
+}
+
+func newStore[T any]() *Store[T] {
+	return &Store[T]{name: reflect.TypeFor[T]().String()}
+}
+
+// Name is the component type's name, e.g. "ecs.Position".
+func (s *Store[T]) Name() string {
+	return s.name
+}
+
+func (s *Store[T]) Len() int {
+	return len(s.entities)
+}
+
+// Entities returns the entities with this component in storage order. The
+// slice is owned by the store; do not modify it.
+func (s *Store[T]) Entities() []Entity {
+	return s.entities
+}
+
+func (s *Store[T]) slot(e Entity) int {
+	i := e.index()
+	if i < 0 || i >= len(s.sparse) {
+		return -1
+	}
+	d := s.sparse[i] - 1
+	if d < 0 || s.entities[d] != e {
+		return -1
+	}
+	return d
+}
+
+func (s *Store[T]) Has(e Entity) bool {
+	return s.slot(e) >= 0
+}
+
+// Get returns e's component, or nil.
+func (s *Store[T]) Get(e Entity) *T {
+	d := s.slot(e)
+	if d < 0 {
+		return nil
+	}
+	return &s.values[d]
+}
+
+// Set adds or replaces e's component and returns a pointer to it. e must
+// be alive; World.Add and the World.AddX helpers check that for you. If
+// another generation of e's index holds a component, e is stale: Set
+// returns nil and leaves the store unchanged.
+func (s *Store[T]) Set(e Entity, v T) *T {
+	i := e.index()
+	if i < len(s.sparse) && s.sparse[i] > 0 {
+		d := s.sparse[i] - 1
+		if s.entities[d] != e {
+			return nil
+		}
+		if s.onRemove != nil {
+			s.onRemove(e, &s.values[d])
+		}
+		s.values[d] = v
+		if s.onSet != nil {
+			s.onSet(e, &s.values[d])
+		}
+		return &s.values[d]
+	}
+	if i >= len(s.sparse) {
+		grown := make([]int, i+1, max(i+1, 2*len(s.sparse)))
+		copy(grown, s.sparse)
+		s.sparse = grown[:cap(grown)]
+	}
+	s.entities = append(s.entities, e)
+	s.values = append(s.values, v)
+	s.sparse[i] = len(s.entities)
+	p := &s.values[len(s.values)-1]
+	if s.onSet != nil {
+		s.onSet(e, p)
+	}
+	return p
+}
+
+func (s *Store[T]) value(e Entity) reflect.Value {
+	v := s.Get(e)
+	if v == nil {
+		return reflect.Value{}
+	}
+	return reflect.ValueOf(v).Elem()
+}
+
+// Remove deletes e's component, reporting whether it had one.
+func (s *Store[T]) Remove(e Entity) bool {
+	d := s.slot(e)
+	if d < 0 {
+		return false
+	}
+	if s.onRemove != nil {
+		s.onRemove(e, &s.values[d])
+	}
+	last := len(s.entities) - 1
+	if d != last {
+		moved := s.entities[last]
+		s.entities[d] = moved
+		s.values[d] = s.values[last]
+		s.sparse[moved.index()] = d + 1
+	}
+	var zero T
+	s.values[last] = zero
+	s.entities = s.entities[:last]
+	s.values = s.values[:last]
+	s.sparse[e.index()] = 0
+	return true
+}
+
+// Each calls fn for every component in storage order. fn must not add or
+// remove components of this type.
+func (s *Store[T]) Each(fn func(e Entity, v *T)) {
+	for i := range s.entities {
+		fn(s.entities[i], &s.values[i])
+	}
+}
+
+// Register returns the world's store for T, creating it on first use.
+func Register[T any](w *World) *Store[T] {
+	t := reflect.TypeFor[T]()
+	if s, ok := w.stores[t]; ok {
+		return s.(*Store[T])
+	}
+	s := newStore[T]()
+	if w.stores == nil {
+		w.stores = map[reflect.Type]componentStore{}
+	}
+	w.stores[t] = s
+	w.storeOrder = append(w.storeOrder, s)
+	return s
+}
+
+// Add sets e's component of type T, registering T if needed. It returns
+// nil, adding nothing, if e is not alive.
+func Add[T any](w *World, e Entity, v T) *T {
+	s := Register[T](w)
+	if !w.Alive(e) {
+		return nil
+	}
+	return s.Set(e, v)
+}
+
+// Get returns e's component of type T, or nil.
+func Get[T any](w *World, e Entity) *T {
+	s, ok := w.stores[reflect.TypeFor[T]()]
+	if !ok {
+		return nil
+	}
+	return s.(*Store[T]).Get(e)
+}
+
+func Has[T any](w *World, e Entity) bool {
+	s, ok := w.stores[reflect.TypeFor[T]()]
+	return ok && s.Has(e)
+}
+
+// Remove deletes e's component of type T.
+func Remove[T any](w *World, e Entity) bool {
+	s, ok := w.stores[reflect.TypeFor[T]()]
+	return ok && s.Remove(e)
+}
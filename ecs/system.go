@@ -0,0 +1,196 @@
+package ecs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UpdateSystem is a system body; dt is in seconds.
+type UpdateSystem func(w *World, dt float64)
+
+// Phase groups systems. Update runs PreUpdate, Update and PostUpdate in
+// that order; World.Render runs Render.
+type Phase int
+
+const (
+	PreUpdate Phase = iota
+	Update
+	PostUpdate
+	Render
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PreUpdate:
+		return "PreUpdate"
+	case Update:
+		return "Update"
+	case PostUpdate:
+		return "PostUpdate"
+	case Render:
+		return "Render"
+	default:
+		return fmt.Sprintf("Phase(%d)", int(p))
+	}
+}
+
+// System is a named step run by the world each update.
+type System struct {
+	Name  string
+	Phase Phase
+	Run   UpdateSystem
+	// Before and After name systems in the same phase this one must run
+	// before or after. Names that are not registered are ignored.
+	Before []string
+	After  []string
+	// Fixed systems run on World.FixedStep ticks, possibly several times
+	// (or not at all) per Update. Other systems run once per Update with the
+	// frame's dt. Render systems run once per World.Render.
+	Fixed    bool
+	Disabled bool
+}
+
+// Scheduler orders a world's systems. Within a phase, systems run in
+// registration order unless Before/After say otherwise.
+type Scheduler struct {
+	systems []*System
+	order   map[Phase][]*System
+	anon    int
+}
+
+// Add registers a system. Systems without a name get one. It fails if the
+// name is taken or the ordering constraints form a cycle.
+func (s *Scheduler) Add(sys System) error {
+	if sys.Run == nil {
+		return fmt.Errorf("system %q has no Run func", sys.Name)
+	}
+	if sys.Name == "" {
+		s.anon++
+		sys.Name = fmt.Sprintf("system-%d", s.anon)
+	}
+	if s.find(sys.Name) >= 0 {
+		return fmt.Errorf("system %q already registered", sys.Name)
+	}
+	s.systems = append(s.systems, &sys)
+	s.order = nil
+	if _, err := s.sort(sys.Phase); err != nil {
+		s.systems = s.systems[:len(s.systems)-1]
+		return err
+	}
+	return nil
+}
+
+// Remove unregisters a system, reporting whether it existed.
+func (s *Scheduler) Remove(name string) bool {
+	i := s.find(name)
+	if i < 0 {
+		return false
+	}
+	s.systems = append(s.systems[:i], s.systems[i+1:]...)
+	s.order = nil
+	return true
+}
+
+// SetEnabled turns a system on or off, reporting whether it exists.
+func (s *Scheduler) SetEnabled(name string, enabled bool) bool {
+	i := s.find(name)
+	if i < 0 {
+		return false
+	}
+	s.systems[i].Disabled = !enabled
+	return true
+}
+
+func (s *Scheduler) Enabled(name string) bool {
+	i := s.find(name)
+	return i >= 0 && !s.systems[i].Disabled
+}
+
+// Order returns the names of a phase's systems in run order, including
+// disabled ones.
+func (s *Scheduler) Order(p Phase) []string {
+	var names []string
+	for _, sys := range s.ordered(p) {
+		names = append(names, sys.Name)
+	}
+	return names
+}
+
+func (s *Scheduler) find(name string) int {
+	for i, sys := range s.systems {
+		if sys.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Scheduler) ordered(p Phase) []*System {
+	if s.order == nil {
+		s.order = map[Phase][]*System{}
+	}
+	if list, ok := s.order[p]; ok {
+		return list
+	}
+	// Add rejects cycles, so sorting cannot fail here.
+	list, _ := s.sort(p)
+	s.order[p] = list
+	return list
+}
+
+// sort orders a phase's systems topologically, breaking ties by
+// registration order.
+func (s *Scheduler) sort(p Phase) ([]*System, error) {
+	var phase []*System
+	index := map[string]int{}
+	for _, sys := range s.systems {
+		if sys.Phase == p {
+			index[sys.Name] = len(phase)
+			phase = append(phase, sys)
+		}
+	}
+	after := make([][]int, len(phase)) // after[i]: systems that must follow i
+	pending := make([]int, len(phase))
+	edge := func(first, then int) {
+		after[first] = append(after[first], then)
+		pending[then]++
+	}
+	for i, sys := range phase {
+		for _, name := range sys.Before {
+			if j, ok := index[name]; ok {
+				edge(i, j)
+			}
+		}
+		for _, name := range sys.After {
+			if j, ok := index[name]; ok {
+				edge(j, i)
+			}
+		}
+	}
+	out := make([]*System, 0, len(phase))
+	done := make([]bool, len(phase))
+	for len(out) < len(phase) {
+		next := -1
+		for i := range phase {
+			if !done[i] && pending[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			var names []string
+			for i, sys := range phase {
+				if !done[i] {
+					names = append(names, sys.Name)
+				}
+			}
+			return nil, fmt.Errorf("%s systems have an ordering cycle: %s", p, strings.Join(names, ", "))
+		}
+		done[next] = true
+		out = append(out, phase[next])
+		for _, j := range after[next] {
+			pending[j]--
+		}
+	}
+	return out, nil
+}
@@ -1,7 +1,8 @@
 package ecs
 
 import (
-	"math"
+	"cmp"
+	"reflect"
 	"sort"
 
 	"github.com/dgrundel/glif/camera"
@@ -9,7 +10,39 @@ import (
 	"github.com/dgrundel/glif/tilemap"
 )
 
-type Entity int
+// Entity is a handle made of an index (low 32 bits) and a generation (high
+// 32 bits). Destroyed indexes are reused with a new generation, so handles
+// kept past Destroy go stale instead of aliasing a new entity.
+type Entity uint64
+
+const indexBits = 32
+
+func newEntity(index int, gen uint32) Entity {
+	return Entity(gen)<<indexBits | Entity(index)
+}
+
+// Index is the entity's slot, shared with earlier entities that were destroyed.
+func (e Entity) Index() int {
+	return int(e & (1<<indexBits - 1))
+}
+
+func (e Entity) Generation() uint32 {
+	return uint32(e >> indexBits)
+}
+
+// compareEntities orders entities by index, then generation. Raw handle
+// values would order by generation first.
+func compareEntities(a, b Entity) int {
+	if c := cmp.Compare(a.Index(), b.Index()); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Generation(), b.Generation())
+}
+
+// index is the entity's slot in component stores.
+func (e Entity) index() int {
+	return e.Index()
+}
 
 type Position struct {
 	X float64
@@ -31,124 +64,313 @@ type TileMapRef struct {
 	Z   int
 }
 
-type UpdateSystem func(w *World, dt float64)
-
 type World struct {
-	next       Entity
-	Positions  map[Entity]*Position
-	Velocities map[Entity]*Velocity
-	Sprites    map[Entity]*SpriteRef
-	TileMaps   map[Entity]*TileMapRef
+	generations []uint32 // current generation per index
+	alive       []bool
+	free        []int
+	updating    int
+	dying       []Entity
+	dyingSet    map[Entity]struct{}
+
+	Positions  *Store[Position]
+	Velocities *Store[Velocity]
+	Sprites    *Store[SpriteRef]
+	TileMaps   *Store[TileMapRef]
 	Camera     camera.Camera
 
-	UpdateSystems []UpdateSystem
+	stores     map[reflect.Type]componentStore
+	storeOrder []componentStore
+	codecs     map[reflect.Type]componentCodec
+
+	queues     map[reflect.Type]eventChannel
+	queueOrder []eventChannel
+	emitted    []eventChannel // one entry per undelivered event, in emit order
+
+	Systems *Scheduler
+	// FixedStep, when > 0, is the tick length for Fixed systems; Update
+	// accumulates dt and runs them once per whole tick. When 0 they run
+	// once per Update with its dt.
+	FixedStep float64
+	Factories map[string]Factory
+	Prefabs   map[string]*Prefab
+
+	sprites     map[string]*render.Sprite // prefab and saved sprites by path
+	contacts    map[contact]bool          // overlapping collider pairs from the last check
+	loadingMaps map[[3]string]*loadingMap // tile maps by map/tiles/entities path during Load
+	children    map[Entity][]Entity       // Parent.Entity -> entities naming it, see indexChildren
+
+	accumulator float64
+	sinceRender float64
 
 	OnResize func(w, h int)
 }
 
 func NewWorld() *World {
-	return &World{
-		Positions:     make(map[Entity]*Position),
-		Velocities:    make(map[Entity]*Velocity),
-		Sprites:       make(map[Entity]*SpriteRef),
-		TileMaps:      make(map[Entity]*TileMapRef),
-		UpdateSystems: []UpdateSystem{},
+	w := &World{
+		Systems:   &Scheduler{},
+		Factories: make(map[string]Factory),
+	}
+	w.Positions = Register[Position](w)
+	w.Velocities = Register[Velocity](w)
+	w.Sprites = Register[SpriteRef](w)
+	w.TileMaps = Register[TileMapRef](w)
+	w.indexChildren()
+	registerBuiltinCodecs(w)
+	for _, sys := range []System{
+		{Name: "movement", Phase: Update, Run: Movement, Fixed: true},
+		{Name: "lifetime", Phase: Update, Run: ExpireLifetimes, Fixed: true, After: []string{"movement"}},
+		{Name: "collision", Phase: PostUpdate, Run: Collisions, Fixed: true},
+		{Name: "animation", Phase: PostUpdate, Run: Animate},
+		{Name: "cull", Phase: PostUpdate, Run: CullOffscreen},
+	} {
+		// The stock systems are fixed, so an error here is a bug.
+		if err := w.Systems.Add(sys); err != nil {
+			panic(err)
+		}
 	}
+	return w
 }
 
+// NewEntity returns a new entity, reusing the index of a destroyed one
+// when available.
 func (w *World) NewEntity() Entity {
-	e := w.next
-	w.next++
-	return e
+	if len(w.free) > 0 {
+		i := w.free[0]
+		w.free = w.free[1:]
+		w.alive[i] = true
+		return newEntity(i, w.generations[i])
+	}
+	w.generations = append(w.generations, 0)
+	w.alive = append(w.alive, true)
+	return newEntity(len(w.generations)-1, 0)
+}
+
+// Alive reports whether e has been created and not yet destroyed. Stale
+// handles to a reused index are not alive.
+func (w *World) Alive(e Entity) bool {
+	i := e.Index()
+	return i < len(w.generations) && w.alive[i] && w.generations[i] == e.Generation()
+}
+
+// liveEntities returns the live entities in index order.
+func (w *World) liveEntities() []Entity {
+	var out []Entity
+	for i, alive := range w.alive {
+		if alive {
+			out = append(out, newEntity(i, w.generations[i]))
+		}
+	}
+	return out
+}
+
+// Destroy removes e and all of its components and frees its index, then
+// destroys e's children. During Update (i.e. from a system) removal is
+// deferred until Update returns, so systems can destroy entities while
+// iterating. Stale handles are ignored.
+func (w *World) Destroy(e Entity) {
+	if !w.Alive(e) {
+		return
+	}
+	if w.updating > 0 {
+		if _, ok := w.dyingSet[e]; !ok {
+			if w.dyingSet == nil {
+				w.dyingSet = map[Entity]struct{}{}
+			}
+			w.dyingSet[e] = struct{}{}
+			w.dying = append(w.dying, e)
+		}
+		return
+	}
+	children := w.Children(e)
+	for _, s := range w.storeOrder {
+		s.Remove(e)
+	}
+	i := e.Index()
+	w.alive[i] = false
+	w.generations[i]++
+	w.free = append(w.free, i)
+	for _, c := range children {
+		w.Destroy(c)
+	}
+}
+
+// lock defers Destroy calls until the matching unlock.
+func (w *World) lock() {
+	w.updating++
+}
+
+func (w *World) unlock() {
+	w.updating--
+	if w.updating == 0 {
+		w.flushDestroyed()
+	}
+}
+
+// flushDestroyed applies Destroy calls deferred during Update.
+func (w *World) flushDestroyed() {
+	for len(w.dying) > 0 {
+		dying := w.dying
+		w.dying = nil
+		for _, e := range dying {
+			delete(w.dyingSet, e)
+			w.Destroy(e)
+		}
+	}
 }
 
 func (w *World) AddPosition(e Entity, x, y float64) {
-	w.Positions[e] = &Position{X: x, Y: y}
+	if w.Alive(e) {
+		w.Positions.Set(e, Position{X: x, Y: y})
+	}
 }
 
 func (w *World) AddVelocity(e Entity, dx, dy float64) {
-	w.Velocities[e] = &Velocity{DX: dx, DY: dy}
+	if w.Alive(e) {
+		w.Velocities.Set(e, Velocity{DX: dx, DY: dy})
+	}
 }
 
 func (w *World) AddSprite(e Entity, sprite *render.Sprite, z int) {
-	w.Sprites[e] = &SpriteRef{Sprite: sprite, Z: z}
+	if w.Alive(e) {
+		w.Sprites.Set(e, SpriteRef{Sprite: sprite, Z: z})
+	}
 }
 
 func (w *World) AddTileMap(e Entity, m *tilemap.Map, z int) {
-	w.TileMaps[e] = &TileMapRef{Map: m, Z: z}
+	if w.Alive(e) {
+		w.TileMaps.Set(e, TileMapRef{Map: m, Z: z})
+	}
 }
 
-func (w *World) AddSystem(sys UpdateSystem) {
-	w.UpdateSystems = append(w.UpdateSystems, sys)
+// AddSystem adds an unnamed per-update system to the Update phase, after
+// those already registered. It fails if sys is nil. Use w.Systems.Add for
+// names, phases and ordering.
+func (w *World) AddSystem(sys UpdateSystem) error {
+	return w.Systems.Add(System{Phase: Update, Run: sys})
 }
 
-func (w *World) Update(dt float64) {
-	// Movement system (built-in).
-	for e, pos := range w.Positions {
-		vel, ok := w.Velocities[e]
-		if !ok {
-			continue
-		}
+// Movement adds each entity's velocity to its position. NewWorld registers
+// it as the "movement" system; remove it to move entities yourself.
+func Movement(w *World, dt float64) {
+	Query2[Position, Velocity](w).Each(func(e Entity, pos *Position, vel *Velocity) {
 		pos.X += vel.DX * dt
 		pos.Y += vel.DY * dt
+	})
+}
+
+// Update runs the PreUpdate, Update and PostUpdate phases, dispatching
+// events after each one. With a FixedStep, the phases repeat once per
+// elapsed tick so Fixed systems see whole ticks, and other systems run in
+// the last pass.
+func (w *World) Update(dt float64) {
+	w.lock()
+	defer w.unlock()
+	w.sinceRender += dt
+	w.clearEvents()
+
+	ticks, step := 1, dt
+	if w.FixedStep > 0 {
+		w.accumulator += dt
+		ticks = int(w.accumulator / w.FixedStep)
+		w.accumulator -= float64(ticks) * w.FixedStep
+		step = w.FixedStep
 	}
+	passes := max(ticks, 1)
+	for pass := 0; pass < passes; pass++ {
+		last := pass == passes-1
+		for _, phase := range []Phase{PreUpdate, Update, PostUpdate} {
+			for _, sys := range w.Systems.ordered(phase) {
+				switch {
+				case sys.Disabled:
+				case sys.Fixed:
+					if pass < ticks {
+						sys.Run(w, step)
+					}
+				case last:
+					sys.Run(w, dt)
+				}
+			}
+			w.DispatchEvents()
+		}
+	}
+}
 
-	for _, sys := range w.UpdateSystems {
-		sys(w, dt)
+// Render runs the Render phase with the time passed to Update since the
+// previous Render. Call it once per frame before drawing; Draw doesn't run
+// systems, so a world drawn into several viewports renders once.
+func (w *World) Render() {
+	dt := w.sinceRender
+	w.sinceRender = 0
+	w.lock()
+	defer w.unlock()
+	for _, sys := range w.Systems.ordered(Render) {
+		if !sys.Disabled {
+			sys.Run(w, dt)
+		}
 	}
 }
 
+// Draw renders sprites and tile maps at their world positions (see
+// Parent), ordered by Z, then entity index. Pass r.Viewport(v) to draw into
+// a viewport with its camera.
 func (w *World) Draw(r *render.Renderer) {
 	type drawItem struct {
 		entity Entity
-		pos    *Position
+		x, y   float64
 		sprite *SpriteRef
 		tile   *TileMapRef
 		z      int
 	}
-	items := make([]drawItem, 0, len(w.Sprites)+len(w.TileMaps))
-	for e, spr := range w.Sprites {
-		pos, ok := w.Positions[e]
-		if !ok || spr.Sprite == nil {
-			continue
+	items := make([]drawItem, 0, w.Sprites.Len()+w.TileMaps.Len())
+	Query2[SpriteRef, Position](w).Each(func(e Entity, spr *SpriteRef, _ *Position) {
+		if spr.Sprite != nil {
+			x, y, _ := w.WorldPosition(e)
+			items = append(items, drawItem{entity: e, x: x, y: y, sprite: spr, z: spr.Z})
 		}
-		items = append(items, drawItem{entity: e, pos: pos, sprite: spr, z: spr.Z})
-	}
-	for e, tm := range w.TileMaps {
-		pos, ok := w.Positions[e]
-		if !ok || tm.Map == nil {
-			continue
+	})
+	Query2[TileMapRef, Position](w).Each(func(e Entity, tm *TileMapRef, _ *Position) {
+		if tm.Map != nil {
+			x, y, _ := w.WorldPosition(e)
+			items = append(items, drawItem{entity: e, x: x, y: y, tile: tm, z: tm.Z})
 		}
-		items = append(items, drawItem{entity: e, pos: pos, tile: tm, z: tm.Z})
-	}
-	sort.Slice(items, func(i, j int) bool {
+	})
+	sort.SliceStable(items, func(i, j int) bool {
 		if items[i].z == items[j].z {
-			return items[i].entity < items[j].entity
+			return compareEntities(items[i].entity, items[j].entity) < 0
 		}
 		return items[i].z < items[j].z
 	})
+	// A renderer camera, e.g. from a render.Viewport, wins over w.Camera.
+	cam := w.Camera
+	if rc := r.Camera(); rc != nil {
+		cam = rc
+	}
+	rc := r.WithCamera(cam)
 	for _, item := range items {
 		if item.sprite != nil {
-			x := item.pos.X
-			y := item.pos.Y
-			if w.Camera != nil {
-				if !w.Camera.Visible(x, y, item.sprite.Sprite.W, item.sprite.Sprite.H) {
-					continue
-				}
-				x, y = w.Camera.WorldToScreen(x, y)
-			}
-			r.DrawSprite(int(math.Floor(x)), int(math.Floor(y)), item.sprite.Sprite)
+			rc.DrawSpriteAt(item.x, item.y, item.sprite.Sprite)
 			continue
 		}
 		if item.tile != nil {
-			x := item.pos.X
-			y := item.pos.Y
-			rc := r
-			if w.Camera != nil {
-				rc = r.WithCamera(w.Camera)
-			}
-			item.tile.Map.Draw(rc, x, y)
+			item.tile.Map.Draw(rc, item.x, item.y)
+		}
+	}
+}
+
+// CameraTarget returns a camera.Target tracking the center of e's sprite, or
+// its position when it has no sprite. Positions are world positions (see
+// Parent).
+func (w *World) CameraTarget(e Entity) camera.Target {
+	return func() (float64, float64, bool) {
+		x, y, ok := w.WorldPosition(e)
+		if !ok {
+			return 0, 0, false
+		}
+		if ref := w.Sprites.Get(e); ref != nil && ref.Sprite != nil {
+			x += float64(ref.Sprite.W) / 2
+			y += float64(ref.Sprite.H) / 2
 		}
+		return x, y, true
 	}
 }
 
@@ -34,6 +34,33 @@ type Quitter interface {
 	ShouldQuit() bool
 }
 
+// Overlay is a tool layer, such as a debug inspector, drawn over the game.
+// While an overlay is shown it receives the input and the game gets none
+// except QuitKey, so the game can still be quit.
+type Overlay interface {
+	HandleInput(state input.State)
+	Draw(r *render.Renderer)
+}
+
+// Pauser is implemented by overlays that can stop game updates while shown.
+type Pauser interface {
+	Paused() bool
+}
+
+// QuitKey reaches the game even while an overlay holds the input. Esc is
+// left to overlays, which use it to cancel.
+const QuitKey input.Key = "key:ctrl+c"
+
+// maxAccum caps the update time carried between frames, so a long stall
+// doesn't trigger a burst of catch-up updates.
+const maxAccum = 0.25
+
+type overlay struct {
+	key   input.Key
+	layer Overlay
+	shown bool
+}
+
 type Engine struct {
 	Screen   *term.Screen
 	Renderer *render.Renderer
@@ -42,6 +69,7 @@ type Engine struct {
 	Input    *input.Manager
 	ShowFPS  bool
 
+	overlays  []*overlay
 	fpsWindow []float64
 	fpsIndex  int
 	fpsCount  int
@@ -72,6 +100,12 @@ func New(game Game, tick time.Duration) (*Engine, error) {
 	}, nil
 }
 
+// AddOverlay registers an overlay that pressing key shows and hides. The
+// most recently added shown overlay gets the input; the others still draw.
+func (e *Engine) AddOverlay(key input.Key, o Overlay) {
+	e.overlays = append(e.overlays, &overlay{key: key, layer: o})
+}
+
 func (e *Engine) Run(game Game) error {
 	defer e.Screen.Fini()
 
@@ -84,7 +118,6 @@ func (e *Engine) Run(game Game) error {
 	if step <= 0 {
 		step = 1.0 / 30.0
 	}
-	const maxAccum = 0.25
 
 	if cs, ok := game.(ClearStyleProvider); ok {
 		e.Frame.Clear.Style = cs.ClearStyle()
@@ -123,31 +156,8 @@ func (e *Engine) Run(game Game) error {
 			}
 
 		updates:
-			state := e.Input.Step(dt)
-			if ia, ok := game.(InputAware); ok {
-				ia.SetInput(state)
-			}
-			if aa, ok := game.(ActionAware); ok {
-				actions := aa.ActionMap()
-				if actions != nil {
-					mapper := input.Mapper{Map: actions}
-					aa.UpdateActionState(mapper.MapState(state))
-				} else {
-					aa.UpdateActionState(input.ActionState{})
-				}
-			}
-
-			accumulator += dt
-			if accumulator > maxAccum {
-				accumulator = maxAccum
-			}
-
 			e.updateFPS(dt)
-
-			for accumulator >= step {
-				game.Update(step)
-				accumulator -= step
-			}
+			accumulator = e.advance(game, e.Input.Step(dt), dt, accumulator, step)
 			if q, ok := game.(Quitter); ok && q.ShouldQuit() {
 				return nil
 			}
@@ -155,6 +165,7 @@ func (e *Engine) Run(game Game) error {
 			e.Renderer.Clear()
 			e.Renderer.SetCamera(nil)
 			game.Draw(e.Renderer)
+			e.drawOverlays()
 			e.drawFPSOverlay()
 			e.Screen.Present(e.Renderer.Frame)
 		case ev := <-events:
@@ -177,6 +188,70 @@ func (e *Engine) Run(game Game) error {
 	}
 }
 
+// advance routes a frame's input through the overlays to game, then runs
+// game.Update once per whole step of accumulated time and returns what is
+// left over. While a shown overlay pauses the game the time is dropped.
+func (e *Engine) advance(game Game, state input.State, dt, accumulator, step float64) float64 {
+	state, paused := e.routeOverlayInput(state)
+	if ia, ok := game.(InputAware); ok {
+		ia.SetInput(state)
+	}
+	if aa, ok := game.(ActionAware); ok {
+		actions := aa.ActionMap()
+		if actions != nil {
+			mapper := input.Mapper{Map: actions}
+			aa.UpdateActionState(mapper.MapState(state))
+		} else {
+			aa.UpdateActionState(input.ActionState{})
+		}
+	}
+
+	if paused {
+		return 0
+	}
+	accumulator = min(accumulator+dt, maxAccum)
+	for accumulator >= step {
+		game.Update(step)
+		accumulator -= step
+	}
+	return accumulator
+}
+
+// routeOverlayInput toggles overlays by hotkey and hands the input to the
+// top shown overlay, returning what is left for the game and whether that
+// overlay pauses it.
+func (e *Engine) routeOverlayInput(state input.State) (input.State, bool) {
+	for _, o := range e.overlays {
+		if state.Pressed[o.key] {
+			o.shown = !o.shown
+			delete(state.Pressed, o.key)
+		}
+	}
+	for i := len(e.overlays) - 1; i >= 0; i-- {
+		o := e.overlays[i]
+		if !o.shown {
+			continue
+		}
+		o.layer.HandleInput(state)
+		p, ok := o.layer.(Pauser)
+		game := input.State{}
+		if state.Pressed[QuitKey] {
+			game.Pressed = map[input.Key]bool{QuitKey: true}
+		}
+		return game, ok && p.Paused()
+	}
+	return state, false
+}
+
+func (e *Engine) drawOverlays() {
+	for _, o := range e.overlays {
+		if o.shown {
+			e.Renderer.SetCamera(nil)
+			o.layer.Draw(e.Renderer)
+		}
+	}
+}
+
 func (e *Engine) updateFPS(dt float64) {
 	if dt <= 0 {
 		return
@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/dgrundel/glif/input"
+	"github.com/dgrundel/glif/render"
+)
+
+// fakeGame counts updates and keeps the last input it was given.
+type fakeGame struct {
+	updates int
+	input   input.State
+}
+
+func (g *fakeGame) Update(dt float64)          { g.updates++ }
+func (g *fakeGame) Draw(r *render.Renderer)    {}
+func (g *fakeGame) Resize(w, h int)            {}
+func (g *fakeGame) SetInput(state input.State) { g.input = state }
+
+// fakeOverlay records the input it handles and pauses when asked.
+type fakeOverlay struct {
+	handled []input.State
+	pause   bool
+}
+
+func (o *fakeOverlay) HandleInput(state input.State) { o.handled = append(o.handled, state) }
+func (o *fakeOverlay) Draw(r *render.Renderer)       {}
+func (o *fakeOverlay) Paused() bool                  { return o.pause }
+
+func pressed(keys ...input.Key) input.State {
+	state := input.State{Pressed: map[input.Key]bool{}}
+	for _, k := range keys {
+		state.Pressed[k] = true
+	}
+	return state
+}
+
+// TestOverlayInput verifies hotkeys toggle overlays, the top shown overlay
+// takes the input and the game only sees input, apart from QuitKey, with no
+// overlay shown.
+func TestOverlayInput(t *testing.T) {
+	e := &Engine{}
+	bottom, top := &fakeOverlay{}, &fakeOverlay{}
+	e.AddOverlay("key:f2", bottom)
+	e.AddOverlay("key:f3", top)
+
+	state, paused := e.routeOverlayInput(pressed("a"))
+	if !state.Pressed["a"] || paused || len(bottom.handled) != 0 || len(top.handled) != 0 {
+		t.Fatalf("with no overlay shown the game should get the input")
+	}
+
+	state, _ = e.routeOverlayInput(pressed("key:f2", "a"))
+	if len(state.Pressed) != 0 || len(bottom.handled) != 1 || bottom.handled[0].Pressed["key:f2"] || !bottom.handled[0].Pressed["a"] {
+		t.Fatalf("f2 should show the overlay and pass it the rest, game=%v overlay=%v", state, bottom.handled)
+	}
+
+	e.routeOverlayInput(pressed("key:f3"))
+	e.routeOverlayInput(pressed("b"))
+	if len(top.handled) != 2 || len(bottom.handled) != 1 || !top.handled[1].Pressed["b"] {
+		t.Fatalf("the top shown overlay should get the input, top=%v bottom=%v", top.handled, bottom.handled)
+	}
+
+	// Quitting works without hiding the overlay first.
+	if state, _ = e.routeOverlayInput(pressed(QuitKey, "key:esc")); !state.Pressed[QuitKey] || state.Pressed["key:esc"] || len(state.Pressed) != 1 {
+		t.Fatalf("only the quit key should reach the game, got %v", state.Pressed)
+	}
+
+	e.routeOverlayInput(pressed("key:f3"))
+	e.routeOverlayInput(pressed("key:f2"))
+	state, _ = e.routeOverlayInput(pressed("c"))
+	if !state.Pressed["c"] || len(bottom.handled) != 2 {
+		t.Fatalf("hiding both overlays should return input to the game")
+	}
+}
+
+// TestAdvancePaused verifies fixed-step updates stop while a shown overlay
+// pauses and resume without a catch-up burst.
+func TestAdvancePaused(t *testing.T) {
+	e := &Engine{}
+	o := &fakeOverlay{}
+	e.AddOverlay("key:f2", o)
+	game := &fakeGame{}
+
+	acc := e.advance(game, pressed("x"), 0.25, 0, 0.1)
+	if game.updates != 2 || !game.input.Pressed["x"] {
+		t.Fatalf("updates=%d input=%v", game.updates, game.input)
+	}
+
+	o.pause = true
+	acc = e.advance(game, pressed("key:f2"), 0.25, acc, 0.1)
+	acc = e.advance(game, pressed("x"), 0.25, acc, 0.1)
+	if game.updates != 2 || acc != 0 || game.input.Pressed["x"] {
+		t.Fatalf("a pausing overlay should stop updates, updates=%d acc=%v", game.updates, acc)
+	}
+
+	o.pause = false
+	e.advance(game, input.State{}, 0.15, acc, 0.1)
+	if game.updates != 3 {
+		t.Fatalf("an overlay that doesn't pause should let updates run, updates=%d", game.updates)
+	}
+}
@@ -0,0 +1,55 @@
+package fov
+
+import (
+	"github.com/dgrundel/glif/grid"
+	"github.com/dgrundel/glif/render"
+)
+
+// Mode controls how tiles outside the view are drawn.
+type Mode int
+
+const (
+	// Hide draws only visible tiles.
+	Hide Mode = iota
+	// Dim draws visible tiles normally, explored tiles darkened and hides the rest.
+	Dim
+)
+
+// Draw renders v.Map at worldX,worldY, hiding or dimming tiles outside the
+// view. It draws maps with a DrawWith method, such as tilemap.Map and
+// tilemap.ChunkedMap.
+func (v *View) Draw(r *render.Renderer, worldX, worldY float64, mode Mode) {
+	if v == nil || v.Map == nil {
+		return
+	}
+	m, ok := v.Map.(interface {
+		DrawWith(r *render.Renderer, worldX, worldY float64, fn func(tx, ty int, sprite *render.Sprite) *render.Sprite)
+	})
+	if !ok {
+		return
+	}
+	v.resize()
+	m.DrawWith(r, worldX, worldY, func(tx, ty int, sprite *render.Sprite) *render.Sprite {
+		if v.Visible(tx, ty) {
+			return sprite
+		}
+		if mode == Dim && v.Explored(tx, ty) {
+			return v.dim(sprite)
+		}
+		return nil
+	})
+}
+
+func (v *View) dim(sprite *render.Sprite) *render.Sprite {
+	if v.dimmed == nil || v.dimmedBy != v.DimFactor {
+		v.dimmed = map[*render.Sprite]*render.Sprite{}
+		v.dimmedBy = v.DimFactor
+	}
+	if out, ok := v.dimmed[sprite]; ok {
+		return out
+	}
+	f := v.DimFactor
+	out := sprite.Restyle(func(s grid.Style) grid.Style { return s.Dim(f) })
+	v.dimmed[sprite] = out
+	return out
+}
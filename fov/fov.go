@@ -0,0 +1,218 @@
+package fov
+
+import (
+	"github.com/dgrundel/glif/render"
+	"github.com/dgrundel/glif/tilemap"
+)
+
+// Opaque reports whether tile x,y blocks sight.
+type Opaque func(m tilemap.Tiles, x, y int) bool
+
+// TileOpaque is the default Opaque. Tiles with `opaque=true` block sight.
+func TileOpaque(m tilemap.Tiles, x, y int) bool {
+	return m.Props(x, y).Bool("opaque", false)
+}
+
+// View tracks which tiles of a map are currently visible and which have
+// been seen before. It covers the map's Extent; for a tilemap.ChunkedMap,
+// explored tiles are remembered while their chunks stay loaded.
+type View struct {
+	Map    tilemap.Tiles
+	Opaque Opaque
+
+	// DimFactor darkens explored tiles outside the view when drawing with Dim.
+	DimFactor float64
+
+	x, y     int // extent origin, in tiles
+	w, h     int
+	visible  []bool
+	explored []bool
+	dimmed   map[*render.Sprite]*render.Sprite
+	dimmedBy float64
+}
+
+func New(m tilemap.Tiles) *View {
+	v := &View{Map: m, Opaque: TileOpaque, DimFactor: 0.4}
+	v.resize()
+	return v
+}
+
+// resize follows the map's extent, keeping what was seen in the overlap.
+func (v *View) resize() {
+	var x, y, w, h int
+	if v.Map != nil {
+		x, y, w, h = v.Map.Extent()
+	}
+	if x == v.x && y == v.y && w == v.w && h == v.h && len(v.visible) == w*h {
+		return
+	}
+	visible := make([]bool, w*h)
+	explored := make([]bool, w*h)
+	for ty := max(y, v.y); ty < min(y+h, v.y+v.h); ty++ {
+		for tx := max(x, v.x); tx < min(x+w, v.x+v.w); tx++ {
+			old := (ty-v.y)*v.w + tx - v.x
+			i := (ty-y)*w + tx - x
+			visible[i] = v.visible[old]
+			explored[i] = v.explored[old]
+		}
+	}
+	v.x, v.y, v.w, v.h = x, y, w, h
+	v.visible, v.explored = visible, explored
+}
+
+// index returns x,y's slot, or false outside the extent.
+func (v *View) index(x, y int) (int, bool) {
+	x -= v.x
+	y -= v.y
+	if x < 0 || y < 0 || x >= v.w || y >= v.h {
+		return 0, false
+	}
+	return y*v.w + x, true
+}
+
+func (v *View) opaque(x, y int) bool {
+	if _, ok := v.index(x, y); !ok || !v.Map.InBounds(x, y) {
+		return true
+	}
+	if v.Opaque == nil {
+		return TileOpaque(v.Map, x, y)
+	}
+	return v.Opaque(v.Map, x, y)
+}
+
+func (v *View) mark(x, y int) {
+	if i, ok := v.index(x, y); ok {
+		v.visible[i] = true
+		v.explored[i] = true
+	}
+}
+
+// Visible reports whether x,y was in view at the last Compute.
+func (v *View) Visible(x, y int) bool {
+	i, ok := v.index(x, y)
+	return ok && v.visible[i]
+}
+
+// Explored reports whether x,y has ever been in view.
+func (v *View) Explored(x, y int) bool {
+	i, ok := v.index(x, y)
+	return ok && v.explored[i]
+}
+
+// Forget clears explored memory, e.g. when entering a new level.
+func (v *View) Forget() {
+	for i := range v.explored {
+		v.explored[i] = false
+		v.visible[i] = false
+	}
+}
+
+// Compute recalculates the visible tiles from origin ox,oy using recursive
+// shadowcasting. A radius <= 0 means unlimited range. Opaque tiles are
+// visible themselves but hide what is behind them.
+func (v *View) Compute(ox, oy, radius int) {
+	v.resize()
+	for i := range v.visible {
+		v.visible[i] = false
+	}
+	if _, ok := v.index(ox, oy); !ok || !v.Map.InBounds(ox, oy) {
+		return
+	}
+	if radius <= 0 {
+		radius = v.w + v.h
+	}
+	v.mark(ox, oy)
+	for _, o := range octants {
+		v.castLight(ox, oy, radius, 1, 1.0, 0.0, o[0], o[1], o[2], o[3])
+	}
+}
+
+// octants holds the xx, xy, yx, yy multipliers that map each octant onto the first.
+var octants = [8][4]int{
+	{1, 0, 0, 1}, {0, 1, 1, 0}, {0, -1, 1, 0}, {-1, 0, 0, 1},
+	{-1, 0, 0, -1}, {0, -1, -1, 0}, {0, 1, -1, 0}, {1, 0, 0, -1},
+}
+
+func (v *View) castLight(ox, oy, radius, row int, start, end float64, xx, xy, yx, yy int) {
+	if start < end {
+		return
+	}
+	radius2 := radius*radius + radius
+	for j := row; j <= radius; j++ {
+		dx, dy := -j-1, -j
+		blocked := false
+		newStart := 0.0
+		for dx <= 0 {
+			dx++
+			x := ox + dx*xx + dy*xy
+			y := oy + dx*yx + dy*yy
+			lSlope := (float64(dx) - 0.5) / (float64(dy) + 0.5)
+			rSlope := (float64(dx) + 0.5) / (float64(dy) - 0.5)
+			if start < rSlope {
+				continue
+			}
+			if end > lSlope {
+				break
+			}
+			if dx*dx+dy*dy <= radius2 {
+				v.mark(x, y)
+			}
+			if blocked {
+				if v.opaque(x, y) {
+					newStart = rSlope
+					continue
+				}
+				blocked = false
+				start = newStart
+			} else if v.opaque(x, y) && j < radius {
+				blocked = true
+				v.castLight(ox, oy, radius, j+1, start, lSlope, xx, xy, yx, yy)
+				newStart = rSlope
+			}
+		}
+		if blocked {
+			break
+		}
+	}
+}
+
+// LineOfSight reports whether x1,y1 can be seen from x0,y0. Only tiles
+// strictly between the two points are tested for opacity.
+func (v *View) LineOfSight(x0, y0, x1, y1 int) bool {
+	v.resize()
+	dx := abs(x1 - x0)
+	dy := -abs(y1 - y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	x, y := x0, y0
+	for {
+		if x == x1 && y == y1 {
+			return true
+		}
+		if (x != x0 || y != y0) && v.opaque(x, y) {
+			return false
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y += sy
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,205 @@
+package fov
+
+import (
+	"testing"
+
+	"github.com/dgrundel/glif/grid"
+	"github.com/dgrundel/glif/render"
+	"github.com/dgrundel/glif/tilemap"
+	"github.com/gdamore/tcell/v3"
+)
+
+var floorStyle = grid.Style{Fg: grid.TCellColor(tcell.NewRGBColor(200, 200, 200)), Bg: grid.TCellColor(tcell.NewRGBColor(40, 40, 40))}
+
+// mapFromRows builds a map where '#' is an opaque wall and anything else is floor.
+func mapFromRows(rows ...string) *tilemap.Map {
+	m := tilemap.New(len(rows[0]), len(rows), 1, 1, 0)
+	m.TileProps[1] = tilemap.Props{"opaque": "true"}
+	m.Tileset[1] = &render.Sprite{W: 1, H: 1, Cells: []grid.Cell{{Ch: '#', Style: floorStyle}}}
+	m.Tileset[2] = &render.Sprite{W: 1, H: 1, Cells: []grid.Cell{{Ch: '.', Style: floorStyle}}}
+	for y, row := range rows {
+		for x, ch := range row {
+			if ch == '#' {
+				m.Set(x, y, 1)
+			} else {
+				m.Set(x, y, 2)
+			}
+		}
+	}
+	return m
+}
+
+type cell struct{ x, y int }
+
+// TestCompute verifies shadowcasting against walls, corners and the radius.
+func TestCompute(t *testing.T) {
+	open := []string{
+		"...........",
+		"...........",
+		"...........",
+		"...........",
+		"...........",
+		"...........",
+		"...........",
+		"...........",
+		"...........",
+		"...........",
+		"...........",
+	}
+	tests := []struct {
+		name      string
+		rows      []string
+		origin    cell
+		radius    int
+		visible   []cell
+		invisible []cell
+	}{
+		{
+			name:    "open room",
+			rows:    open,
+			origin:  cell{5, 5},
+			visible: []cell{{0, 0}, {10, 10}, {0, 10}, {10, 0}},
+		},
+		{
+			name: "wall blocks",
+			rows: []string{
+				"...#...",
+				"...#...",
+				"...#...",
+			},
+			origin:    cell{1, 1},
+			visible:   []cell{{0, 0}, {2, 2}, {3, 0}, {3, 1}, {3, 2}},
+			invisible: []cell{{4, 1}, {5, 0}, {6, 2}},
+		},
+		{
+			name: "corner",
+			rows: []string{
+				"#####",
+				"....#",
+				"###.#",
+				"###..",
+			},
+			origin:    cell{0, 1},
+			visible:   []cell{{3, 1}, {4, 1}, {2, 2}},
+			invisible: []cell{{4, 3}},
+		},
+		{
+			name:      "radius",
+			rows:      open,
+			origin:    cell{5, 5},
+			radius:    3,
+			visible:   []cell{{8, 5}, {5, 2}, {7, 7}},
+			invisible: []cell{{9, 5}, {5, 1}, {8, 8}, {0, 0}},
+		},
+		{
+			name:      "origin outside map",
+			rows:      open,
+			origin:    cell{-1, 5},
+			invisible: []cell{{0, 5}, {5, 5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := New(mapFromRows(tt.rows...))
+			v.Compute(tt.origin.x, tt.origin.y, tt.radius)
+			for _, c := range tt.visible {
+				if !v.Visible(c.x, c.y) {
+					t.Errorf("%v should be visible", c)
+				}
+			}
+			for _, c := range tt.invisible {
+				if v.Visible(c.x, c.y) {
+					t.Errorf("%v should not be visible", c)
+				}
+			}
+		})
+	}
+}
+
+// TestLineOfSight verifies only opaque tiles strictly between the points block.
+func TestLineOfSight(t *testing.T) {
+	v := New(mapFromRows(
+		".....",
+		"..#..",
+		".....",
+	))
+	tests := []struct {
+		name     string
+		from, to cell
+		want     bool
+	}{
+		{"clear row", cell{0, 0}, cell{4, 0}, true},
+		{"wall between", cell{0, 1}, cell{4, 1}, false},
+		{"adjacent to wall", cell{1, 1}, cell{2, 1}, true},
+		{"from the wall", cell{2, 1}, cell{4, 1}, true},
+		{"diagonal past the wall", cell{0, 0}, cell{4, 2}, false},
+		{"same cell", cell{3, 2}, cell{3, 2}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := v.LineOfSight(tt.from.x, tt.from.y, tt.to.x, tt.to.y); got != tt.want {
+				t.Fatalf("LineOfSight(%v, %v)=%v want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestExploredDimmed verifies remembered tiles stay explored and draw dimmed.
+func TestExploredDimmed(t *testing.T) {
+	v := New(mapFromRows(
+		"...#...",
+		"...#...",
+	))
+	v.Compute(1, 0, 0)
+	v.Compute(5, 0, 0)
+	if v.Visible(1, 0) || !v.Explored(1, 0) {
+		t.Fatalf("1,0 visible=%v explored=%v, want remembered", v.Visible(1, 0), v.Explored(1, 0))
+	}
+	if !v.Visible(5, 1) {
+		t.Fatalf("5,1 should be visible")
+	}
+
+	draw := func(mode Mode) *grid.Frame {
+		frame := grid.NewFrame(7, 2, grid.Cell{Ch: ' '})
+		v.Draw(render.NewRenderer(frame), 0, 0, mode)
+		return frame
+	}
+	frame := draw(Dim)
+	if c := frame.At(5, 1); c.Ch != '.' || c.Style != floorStyle {
+		t.Fatalf("visible cell=%+v", c)
+	}
+	if c := frame.At(1, 0); c.Ch != '.' || c.Style != floorStyle.Dim(v.DimFactor) {
+		t.Fatalf("remembered cell=%+v, want dimmed floor", c)
+	}
+	if c := draw(Hide).At(1, 0); c.Ch != ' ' {
+		t.Fatalf("Hide should skip remembered cells, got %q", c.Ch)
+	}
+
+	v.Forget()
+	if v.Explored(1, 0) {
+		t.Fatalf("Forget should clear explored tiles")
+	}
+}
+
+// TestChunkedView verifies views follow a chunked map's loaded chunks and
+// keep explored tiles as more chunks load.
+func TestChunkedView(t *testing.T) {
+	c := tilemap.NewChunked(4, 4, 1, 1, 0, nil)
+	c.TileProps[1] = tilemap.Props{"opaque": "true"}
+	c.Chunk(tilemap.ChunkCoord{X: -1, Y: 0})
+	c.Set(-2, 1, 1)
+
+	v := New(c)
+	v.Compute(-4, 1, 0)
+	if !v.Visible(-3, 1) || !v.Visible(-2, 1) || v.Visible(-1, 1) {
+		t.Fatalf("wall at -2,1 should hide -1,1")
+	}
+	c.Chunk(tilemap.ChunkCoord{X: 0, Y: 0})
+	v.Compute(2, 2, 2)
+	if !v.Explored(-3, 1) || v.Visible(-3, 1) || !v.Visible(1, 1) {
+		t.Fatalf("explored=%v visible=%v after loading a chunk", v.Explored(-3, 1), v.Visible(1, 1))
+	}
+	if len(c.Chunks()) != 2 {
+		t.Fatalf("Compute generated chunks: %v", c.Chunks())
+	}
+}
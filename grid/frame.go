@@ -38,6 +38,37 @@ func (s Style) Resolve(base Style) Style {
 	return s
 }
 
+// Scale multiplies the RGB components of c by f. Colors without RGB
+// components (reset, inherit) are returned unchanged.
+func (c Color) Scale(f float64) Color {
+	if c.Kind != ColorTCell {
+		return c
+	}
+	r, g, b := c.TCellColor.RGB()
+	if r < 0 {
+		return c
+	}
+	scale := func(v int32) int32 {
+		out := int32(float64(v) * f)
+		if out < 0 {
+			return 0
+		}
+		if out > 255 {
+			return 255
+		}
+		return out
+	}
+	return TCellColor(tcell.NewRGBColor(scale(r), scale(g), scale(b)))
+}
+
+// Dim darkens both colors of s by factor f (0 is black, 1 is unchanged).
+func (s Style) Dim(f float64) Style {
+	s.Fg = s.Fg.Scale(f)
+	s.Bg = s.Bg.Scale(f)
+	s.Bold = false
+	return s
+}
+
 func (s Style) ToTCell() tcell.Style {
 	fg := s.Fg
 	bg := s.Bg
@@ -0,0 +1,85 @@
+package path
+
+import "container/heap"
+
+// Find returns the cheapest path from start to goal, including both ends,
+// using A*. ok is false when goal cannot be reached.
+func (g *Grid) Find(start, goal Point) ([]Point, bool) {
+	if !g.Walkable(start.X, start.Y) || !g.Walkable(goal.X, goal.Y) {
+		return nil, false
+	}
+	if start == goal {
+		return []Point{start}, true
+	}
+	index := func(p Point) int {
+		i, _ := g.index(p.X, p.Y)
+		return i
+	}
+
+	cost := map[int]float64{index(start): 0}
+	from := map[int]Point{}
+	closed := map[int]bool{}
+	open := &queue{}
+	heap.Push(open, item{p: start, priority: g.heuristic(start, goal)})
+
+	for open.Len() > 0 {
+		cur := heap.Pop(open).(item).p
+		ci := index(cur)
+		if closed[ci] {
+			continue
+		}
+		if cur == goal {
+			return reconstruct(from, start, goal, index), true
+		}
+		closed[ci] = true
+		g.neighbors(cur, func(n Point, step float64) {
+			ni := index(n)
+			if closed[ni] {
+				return
+			}
+			next := cost[ci] + step
+			if old, ok := cost[ni]; ok && next >= old {
+				return
+			}
+			cost[ni] = next
+			from[ni] = cur
+			heap.Push(open, item{p: n, priority: next + g.heuristic(n, goal)})
+		})
+	}
+	return nil, false
+}
+
+func reconstruct(from map[int]Point, start, goal Point, index func(Point) int) []Point {
+	out := []Point{goal}
+	for cur := goal; cur != start; {
+		cur = from[index(cur)]
+		out = append(out, cur)
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+type item struct {
+	p        Point
+	priority float64
+}
+
+type queue []item
+
+func (q queue) Len() int { return len(q) }
+
+func (q queue) Less(i, j int) bool { return q[i].priority < q[j].priority }
+
+func (q queue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *queue) Push(x any) { *q = append(*q, x.(item)) }
+
+func (q *queue) Pop() any {
+	old := *q
+	n := len(old)
+	it := old[n-1]
+	*q = old[:n-1]
+	return it
+}
@@ -0,0 +1,93 @@
+package path
+
+import (
+	"container/heap"
+	"math"
+)
+
+// FlowField holds the cheapest cost from every tile to the nearest goal.
+// Many agents can share one field to move toward the same target. Dist
+// covers the map's Extent: X,Y is its top-left tile and W,H its size.
+type FlowField struct {
+	X    int
+	Y    int
+	W    int
+	H    int
+	Dist []float64
+
+	grid *Grid
+}
+
+// FlowField runs Dijkstra outward from goals over the grid.
+func (g *Grid) FlowField(goals ...Point) *FlowField {
+	g.refresh()
+	if g.Map == nil {
+		return &FlowField{grid: g}
+	}
+	f := &FlowField{X: g.x, Y: g.y, W: g.w, H: g.h, Dist: make([]float64, g.w*g.h), grid: g}
+	for i := range f.Dist {
+		f.Dist[i] = math.Inf(1)
+	}
+	open := &queue{}
+	for _, goal := range goals {
+		if !g.Walkable(goal.X, goal.Y) {
+			continue
+		}
+		i, _ := g.index(goal.X, goal.Y)
+		f.Dist[i] = 0
+		heap.Push(open, item{p: goal})
+	}
+	for open.Len() > 0 {
+		it := heap.Pop(open).(item)
+		cur := it.p
+		if ci, _ := g.index(cur.X, cur.Y); it.priority > f.Dist[ci] {
+			continue
+		}
+		// Step costs are symmetric except for the entered tile, so charge the
+		// cost of the tile being left to model walking from n toward cur.
+		leave, _ := g.CostAt(cur.X, cur.Y)
+		g.neighbors(cur, func(n Point, step float64) {
+			enter, _ := g.CostAt(n.X, n.Y)
+			next := it.priority + step/enter*leave
+			ni, _ := g.index(n.X, n.Y)
+			if next < f.Dist[ni] {
+				f.Dist[ni] = next
+				heap.Push(open, item{p: n, priority: next})
+			}
+		})
+	}
+	return f
+}
+
+// Distance returns the cost from x,y to the nearest goal.
+func (f *FlowField) Distance(x, y int) (float64, bool) {
+	if f == nil {
+		return 0, false
+	}
+	x -= f.X
+	y -= f.Y
+	if x < 0 || y < 0 || x >= f.W || y >= f.H {
+		return 0, false
+	}
+	d := f.Dist[y*f.W+x]
+	return d, !math.IsInf(d, 1)
+}
+
+// Next returns the neighbor of x,y that leads toward the nearest goal.
+// ok is false at a goal or when no goal is reachable.
+func (f *FlowField) Next(x, y int) (Point, bool) {
+	best, ok := f.Distance(x, y)
+	if !ok || best == 0 {
+		return Point{}, false
+	}
+	var out Point
+	found := false
+	f.grid.neighbors(Point{x, y}, func(n Point, _ float64) {
+		if d, ok := f.Distance(n.X, n.Y); ok && d < best {
+			best = d
+			out = n
+			found = true
+		}
+	})
+	return out, found
+}
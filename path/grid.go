@@ -0,0 +1,167 @@
+package path
+
+import (
+	"math"
+
+	"github.com/dgrundel/glif/tilemap"
+)
+
+// Point is a tile coordinate.
+type Point struct {
+	X int
+	Y int
+}
+
+// Moves selects which neighbors a step may reach.
+type Moves int
+
+const (
+	Four  Moves = 4
+	Eight Moves = 8
+)
+
+// CostFunc returns the cost of entering tile x,y. ok is false when the tile
+// cannot be entered.
+type CostFunc func(m tilemap.Tiles, x, y int) (cost float64, ok bool)
+
+// TileCost is the default CostFunc. Tiles with `solid=true` or `walkable=false`
+// are blocked; otherwise the `cost` property is used (default 1).
+func TileCost(m tilemap.Tiles, x, y int) (float64, bool) {
+	props := m.Props(x, y)
+	if props.Bool("solid", false) || !props.Bool("walkable", true) {
+		return 0, false
+	}
+	cost := props.Float("cost", 1)
+	if cost <= 0 {
+		return 0, false
+	}
+	return cost, true
+}
+
+// Grid caches per-tile movement costs over a map's Extent. The cache is
+// rebuilt automatically when the map's Version changes, e.g. from Set or a
+// tilemap.ChunkedMap loading chunks; call Invalidate after editing Map.Tiles
+// directly or changing Cost. Tiles that are not InBounds are blocked.
+type Grid struct {
+	Map   tilemap.Tiles
+	Moves Moves
+	Cost  CostFunc
+
+	costs      []float64
+	x, y, w, h int // cached extent
+	minCost    float64
+	version    int
+	built      bool
+}
+
+func NewGrid(m tilemap.Tiles, moves Moves) *Grid {
+	if moves != Eight {
+		moves = Four
+	}
+	return &Grid{Map: m, Moves: moves, Cost: TileCost}
+}
+
+// Invalidate forces the cost cache to be rebuilt on next use.
+func (g *Grid) Invalidate() {
+	g.built = false
+}
+
+func (g *Grid) refresh() {
+	if g.Map == nil {
+		g.costs = nil
+		return
+	}
+	ex, ey, ew, eh := g.Map.Extent()
+	if g.built && g.version == g.Map.Version() && ex == g.x && ey == g.y && ew == g.w && eh == g.h {
+		return
+	}
+	cost := g.Cost
+	if cost == nil {
+		cost = TileCost
+	}
+	g.x, g.y, g.w, g.h = ex, ey, ew, eh
+	g.costs = make([]float64, ew*eh)
+	g.minCost = math.Inf(1)
+	for y := ey; y < ey+eh; y++ {
+		for x := ex; x < ex+ew; x++ {
+			c, ok := -1.0, false
+			if g.Map.InBounds(x, y) {
+				c, ok = cost(g.Map, x, y)
+			}
+			if !ok {
+				c = -1
+			} else if c < g.minCost {
+				g.minCost = c
+			}
+			g.costs[(y-ey)*ew+x-ex] = c
+		}
+	}
+	if math.IsInf(g.minCost, 1) {
+		g.minCost = 1
+	}
+	g.version = g.Map.Version()
+	g.built = true
+}
+
+// index returns x,y's slot in the cached extent, or false outside it.
+func (g *Grid) index(x, y int) (int, bool) {
+	x -= g.x
+	y -= g.y
+	if x < 0 || y < 0 || x >= g.w || y >= g.h {
+		return 0, false
+	}
+	return y*g.w + x, true
+}
+
+// CostAt returns the cached cost of entering x,y.
+func (g *Grid) CostAt(x, y int) (float64, bool) {
+	g.refresh()
+	i, ok := g.index(x, y)
+	if g.Map == nil || !ok {
+		return 0, false
+	}
+	c := g.costs[i]
+	return c, c >= 0
+}
+
+func (g *Grid) Walkable(x, y int) bool {
+	_, ok := g.CostAt(x, y)
+	return ok
+}
+
+var (
+	orthogonal = []Point{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+	diagonal   = []Point{{1, -1}, {1, 1}, {-1, 1}, {-1, -1}}
+)
+
+// neighbors calls fn for each tile reachable in one step from p with the cost
+// of that step. Diagonal steps may not cut past blocked corners.
+func (g *Grid) neighbors(p Point, fn func(n Point, cost float64)) {
+	for _, d := range orthogonal {
+		n := Point{p.X + d.X, p.Y + d.Y}
+		if c, ok := g.CostAt(n.X, n.Y); ok {
+			fn(n, c)
+		}
+	}
+	if g.Moves != Eight {
+		return
+	}
+	for _, d := range diagonal {
+		n := Point{p.X + d.X, p.Y + d.Y}
+		c, ok := g.CostAt(n.X, n.Y)
+		if !ok || !g.Walkable(p.X+d.X, p.Y) || !g.Walkable(p.X, p.Y+d.Y) {
+			continue
+		}
+		fn(n, c*math.Sqrt2)
+	}
+}
+
+// heuristic estimates the remaining cost between a and b.
+func (g *Grid) heuristic(a, b Point) float64 {
+	dx := math.Abs(float64(a.X - b.X))
+	dy := math.Abs(float64(a.Y - b.Y))
+	if g.Moves == Eight {
+		return g.minCost * (dx + dy + (math.Sqrt2-2)*math.Min(dx, dy))
+	}
+	return g.minCost * (dx + dy)
+}
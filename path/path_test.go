@@ -0,0 +1,139 @@
+package path
+
+import (
+	"testing"
+
+	"github.com/dgrundel/glif/tilemap"
+)
+
+// mapFromRows builds a map where '#' is solid, '~' costs 5 and anything else costs 1.
+func mapFromRows(rows ...string) *tilemap.Map {
+	m := tilemap.New(len(rows[0]), len(rows), 1, 1, 0)
+	m.TileProps[1] = tilemap.Props{"solid": "true"}
+	m.TileProps[2] = tilemap.Props{"cost": "5"}
+	for y, row := range rows {
+		for x, ch := range row {
+			switch ch {
+			case '#':
+				m.Set(x, y, 1)
+			case '~':
+				m.Set(x, y, 2)
+			}
+		}
+	}
+	return m
+}
+
+// TestFindAroundWall verifies A* routes around obstacles and avoids costly tiles.
+func TestFindAroundWall(t *testing.T) {
+	m := mapFromRows(
+		".....",
+		".###.",
+		"..~..",
+	)
+	g := NewGrid(m, Four)
+	p, ok := g.Find(Point{0, 1}, Point{4, 1})
+	if !ok {
+		t.Fatalf("Find failed")
+	}
+	if len(p) != 7 {
+		t.Fatalf("len=%d want=7 path=%v", len(p), p)
+	}
+	for _, pt := range p {
+		if pt.Y == 2 {
+			t.Fatalf("path crossed the expensive row: %v", p)
+		}
+	}
+}
+
+// TestGridInvalidatesOnSet verifies Map.Set refreshes the cached costs.
+func TestGridInvalidatesOnSet(t *testing.T) {
+	m := mapFromRows(
+		"..#..",
+	)
+	g := NewGrid(m, Eight)
+	if _, ok := g.Find(Point{0, 0}, Point{4, 0}); ok {
+		t.Fatalf("path should be blocked")
+	}
+	m.Set(2, 0, 0)
+	p, ok := g.Find(Point{0, 0}, Point{4, 0})
+	if !ok || len(p) != 5 {
+		t.Fatalf("path=%v ok=%v after clearing wall", p, ok)
+	}
+}
+
+// TestFlowFieldAndSmooth verifies flow fields step toward the goal and smoothing drops waypoints
+// without cutting through costly tiles.
+func TestFlowFieldAndSmooth(t *testing.T) {
+	m := mapFromRows(
+		"......",
+		"......",
+		"......",
+	)
+	g := NewGrid(m, Eight)
+	f := g.FlowField(Point{5, 2})
+	next, ok := f.Next(0, 0)
+	if !ok || next != (Point{1, 1}) {
+		t.Fatalf("Next=%v ok=%v want={1 1}", next, ok)
+	}
+	if d, _ := f.Distance(5, 0); d != 2 {
+		t.Fatalf("Distance=%v want=2", d)
+	}
+
+	p := []Point{{0, 0}, {1, 0}, {2, 0}, {3, 1}, {4, 2}, {5, 2}}
+	s := g.Smooth(p)
+	if len(s) != 2 {
+		t.Fatalf("Smooth=%v want endpoints only", s)
+	}
+
+	// A path around costly water keeps its corners: the straight line
+	// through the water would cost more.
+	m = mapFromRows(
+		".....",
+		".~~~.",
+		".....",
+	)
+	g = NewGrid(m, Eight)
+	around, ok := g.Find(Point{0, 1}, Point{4, 1})
+	if !ok {
+		t.Fatalf("Find failed")
+	}
+	for _, pt := range g.Smooth(around) {
+		if pt.Y == 1 && pt.X > 0 && pt.X < 4 {
+			t.Fatalf("Smooth=%v cut through the water", g.Smooth(around))
+		}
+	}
+	if s := g.Smooth(around); len(s) <= 2 {
+		t.Fatalf("Smooth=%v should keep the detour", s)
+	}
+}
+
+// TestChunkedGrid verifies paths cross chunk borders at negative coordinates
+// and treat unloaded chunks as blocked.
+func TestChunkedGrid(t *testing.T) {
+	c := tilemap.NewChunked(4, 4, 1, 1, 0, func(coord tilemap.ChunkCoord, chunk *tilemap.Map) {
+		if coord.X == -1 {
+			chunk.Set(3, 0, 1) // a wall at world x=-1, y=0
+		}
+	})
+	c.TileProps[1] = tilemap.Props{"solid": "true"}
+	c.Chunk(tilemap.ChunkCoord{X: -1, Y: 0})
+	c.Chunk(tilemap.ChunkCoord{X: 0, Y: 0})
+
+	g := NewGrid(c, Four)
+	p, ok := g.Find(Point{-3, 0}, Point{2, 0})
+	if !ok || len(p) != 8 {
+		t.Fatalf("path=%v ok=%v want 8 steps around the wall", p, ok)
+	}
+	if _, ok := g.Find(Point{0, 0}, Point{5, 0}); ok {
+		t.Fatalf("goal in an unloaded chunk should be unreachable")
+	}
+	if d, ok := g.FlowField(Point{-4, 0}).Distance(3, 3); !ok || d != 10 {
+		t.Fatalf("flow distance=%v ok=%v want 10", d, ok)
+	}
+
+	c.Chunk(tilemap.ChunkCoord{X: 1, Y: 0})
+	if _, ok := g.Find(Point{0, 0}, Point{5, 0}); !ok {
+		t.Fatalf("loading a chunk should refresh the grid")
+	}
+}
@@ -0,0 +1,93 @@
+package path
+
+import "math"
+
+// Smooth removes waypoints that can be skipped by walking in a straight line
+// over walkable tiles that costs no more than the path it replaces, so a
+// line never cuts through costly tiles the path went around. The first and
+// last points are always kept.
+func (g *Grid) Smooth(path []Point) []Point {
+	if len(path) <= 2 {
+		return path
+	}
+	out := []Point{path[0]}
+	anchor := path[0]
+	walked := g.stepCost(path[0], path[1]) // path cost from anchor to path[i-1]
+	for i := 2; i < len(path); i++ {
+		walked += g.stepCost(path[i-1], path[i])
+		if cost, ok := g.lineCost(anchor, path[i]); !ok || cost > walked+1e-9 {
+			anchor = path[i-1]
+			out = append(out, anchor)
+			walked = g.stepCost(path[i-1], path[i])
+		}
+	}
+	return append(out, path[len(path)-1])
+}
+
+// stepCost is the cost of moving from a to the adjacent tile b, as neighbors
+// prices it: b's cost, times √2 on a diagonal.
+func (g *Grid) stepCost(a, b Point) float64 {
+	c, ok := g.CostAt(b.X, b.Y)
+	if !ok {
+		return math.Inf(1)
+	}
+	if a.X != b.X && a.Y != b.Y {
+		c *= math.Sqrt2
+	}
+	return c
+}
+
+// lineCost sums the step costs along the segment a-b. It reports false if a
+// tile it touches is not walkable; diagonal steps also require both side
+// tiles, matching neighbors.
+func (g *Grid) lineCost(a, b Point) (float64, bool) {
+	dx := abs(b.X - a.X)
+	dy := -abs(b.Y - a.Y)
+	sx := sign(b.X - a.X)
+	sy := sign(b.Y - a.Y)
+	err := dx + dy
+	x, y := a.X, a.Y
+	if !g.Walkable(x, y) {
+		return 0, false
+	}
+	total := 0.0
+	for x != b.X || y != b.Y {
+		e2 := 2 * err
+		stepX := e2 >= dy
+		stepY := e2 <= dx
+		if stepX && stepY && (!g.Walkable(x+sx, y) || !g.Walkable(x, y+sy)) {
+			return 0, false
+		}
+		from := Point{x, y}
+		if stepX {
+			err += dy
+			x += sx
+		}
+		if stepY {
+			err += dx
+			y += sy
+		}
+		if !g.Walkable(x, y) {
+			return 0, false
+		}
+		total += g.stepCost(from, Point{x, y})
+	}
+	return total, true
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
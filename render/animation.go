@@ -199,6 +199,7 @@ func buildCollisionMask(spriteLines, collisionLines [][]rune, widthMask [][]int,
 		return nil, fmt.Errorf("sprite and collision sizes differ: sprite=%dx%d collision=%dx%d", sw, sh, cw, ch)
 	}
 	cells := make([]bool, cellW*sh)
+	kinds := make([]rune, cellW*sh)
 	for y := 0; y < sh; y++ {
 		col := 0
 		for x := 0; x < sw; x++ {
@@ -213,6 +214,7 @@ func buildCollisionMask(spriteLines, collisionLines [][]rune, widthMask [][]int,
 				col++
 				if collides {
 					cells[idx] = true
+					kinds[idx] = ch
 				}
 			}
 		}
@@ -220,5 +222,5 @@ func buildCollisionMask(spriteLines, collisionLines [][]rune, widthMask [][]int,
 			col++
 		}
 	}
-	return &CollisionMask{W: cellW, H: sh, Cells: cells}, nil
+	return &CollisionMask{W: cellW, H: sh, Cells: cells, Kinds: kinds}, nil
 }
@@ -1,9 +1,14 @@
 package render
 
+import "strings"
+
 type CollisionMask struct {
 	W     int
 	H     int
 	Cells []bool
+	// Kinds holds the `.collision` character for each collidable cell (0 otherwise).
+	// Different characters act as different layers, e.g. `h` hurtbox vs `s` solid.
+	Kinds []rune
 }
 
 func (m *CollisionMask) At(x, y int) bool {
@@ -12,3 +17,26 @@ func (m *CollisionMask) At(x, y int) bool {
 	}
 	return m.Cells[y*m.W+x]
 }
+
+// Kind returns the layer character at x,y, or 0 when the cell is not collidable.
+// Masks built without Kinds report every collidable cell as 'x', the usual
+// `.collision` character.
+func (m *CollisionMask) Kind(x, y int) rune {
+	if !m.At(x, y) {
+		return 0
+	}
+	if m.Kinds == nil {
+		return 'x'
+	}
+	return m.Kinds[y*m.W+x]
+}
+
+// AtKind reports whether x,y is collidable on one of the given layer characters.
+// An empty kinds string matches every collidable cell.
+func (m *CollisionMask) AtKind(x, y int, kinds string) bool {
+	if kinds == "" {
+		return m.At(x, y)
+	}
+	k := m.Kind(x, y)
+	return k != 0 && strings.ContainsRune(kinds, k)
+}
@@ -1,8 +1,6 @@
 package render
 
 import (
-	"math"
-
 	"github.com/dgrundel/glif/grid"
 	"github.com/gdamore/tcell/v3"
 )
@@ -32,10 +30,8 @@ func (r *Renderer) Rect(x, y, w, h int, style grid.Style, opts ...RectOptions) {
 		if !r.camera.Visible(float64(x), float64(y), w, h) {
 			return
 		}
-		wx, wy := r.camera.WorldToScreen(float64(x), float64(y))
-		x = int(math.Floor(wx))
-		y = int(math.Floor(wy))
 	}
+	x, y = r.project(x, y)
 	opt := rectDefaults()
 	if len(opts) > 0 {
 		opt = mergeRectOptions(opt, opts[0])
@@ -48,8 +44,7 @@ func (r *Renderer) Rect(x, y, w, h int, style grid.Style, opts ...RectOptions) {
 		}
 		for row := 0; row < h; row++ {
 			for col := 0; col < w; col++ {
-				resolved := style.Resolve(r.Frame.At(x+col, y+row).Style)
-				r.Frame.Set(x+col, y+row, grid.Cell{Ch: fillRune, Style: resolved})
+				r.put(x+col, y+row, grid.Cell{Ch: fillRune, Style: style})
 			}
 		}
 		return
@@ -58,17 +53,17 @@ func (r *Renderer) Rect(x, y, w, h int, style grid.Style, opts ...RectOptions) {
 	if w < 2 || h < 2 {
 		return
 	}
-	r.Frame.Set(x, y, grid.Cell{Ch: opt.TLCorner, Style: style.Resolve(r.Frame.At(x, y).Style)})
-	r.Frame.Set(x+w-1, y, grid.Cell{Ch: opt.TRCorner, Style: style.Resolve(r.Frame.At(x+w-1, y).Style)})
-	r.Frame.Set(x, y+h-1, grid.Cell{Ch: opt.BLCorner, Style: style.Resolve(r.Frame.At(x, y+h-1).Style)})
-	r.Frame.Set(x+w-1, y+h-1, grid.Cell{Ch: opt.BRCorner, Style: style.Resolve(r.Frame.At(x+w-1, y+h-1).Style)})
+	r.put(x, y, grid.Cell{Ch: opt.TLCorner, Style: style})
+	r.put(x+w-1, y, grid.Cell{Ch: opt.TRCorner, Style: style})
+	r.put(x, y+h-1, grid.Cell{Ch: opt.BLCorner, Style: style})
+	r.put(x+w-1, y+h-1, grid.Cell{Ch: opt.BRCorner, Style: style})
 	for i := 1; i < w-1; i++ {
-		r.Frame.Set(x+i, y, grid.Cell{Ch: opt.HLine, Style: style.Resolve(r.Frame.At(x+i, y).Style)})
-		r.Frame.Set(x+i, y+h-1, grid.Cell{Ch: opt.HLine, Style: style.Resolve(r.Frame.At(x+i, y+h-1).Style)})
+		r.put(x+i, y, grid.Cell{Ch: opt.HLine, Style: style})
+		r.put(x+i, y+h-1, grid.Cell{Ch: opt.HLine, Style: style})
 	}
 	for j := 1; j < h-1; j++ {
-		r.Frame.Set(x, y+j, grid.Cell{Ch: opt.VLine, Style: style.Resolve(r.Frame.At(x, y+j).Style)})
-		r.Frame.Set(x+w-1, y+j, grid.Cell{Ch: opt.VLine, Style: style.Resolve(r.Frame.At(x+w-1, y+j).Style)})
+		r.put(x, y+j, grid.Cell{Ch: opt.VLine, Style: style})
+		r.put(x+w-1, y+j, grid.Cell{Ch: opt.VLine, Style: style})
 	}
 }
 
@@ -81,17 +76,14 @@ func (r *Renderer) HLine(x, y, length int, style grid.Style, opts ...LineOptions
 		if !r.camera.Visible(float64(x), float64(y), length, 1) {
 			return
 		}
-		wx, wy := r.camera.WorldToScreen(float64(x), float64(y))
-		x = int(math.Floor(wx))
-		y = int(math.Floor(wy))
 	}
+	x, y = r.project(x, y)
 	ch := tcell.RuneHLine
 	if len(opts) > 0 && opts[0].Rune != 0 {
 		ch = opts[0].Rune
 	}
 	for i := 0; i < length; i++ {
-		resolved := style.Resolve(r.Frame.At(x+i, y).Style)
-		r.Frame.Set(x+i, y, grid.Cell{Ch: ch, Style: resolved})
+		r.put(x+i, y, grid.Cell{Ch: ch, Style: style})
 	}
 }
 
@@ -104,17 +96,14 @@ func (r *Renderer) VLine(x, y, length int, style grid.Style, opts ...LineOptions
 		if !r.camera.Visible(float64(x), float64(y), 1, length) {
 			return
 		}
-		wx, wy := r.camera.WorldToScreen(float64(x), float64(y))
-		x = int(math.Floor(wx))
-		y = int(math.Floor(wy))
 	}
+	x, y = r.project(x, y)
 	ch := tcell.RuneVLine
 	if len(opts) > 0 && opts[0].Rune != 0 {
 		ch = opts[0].Rune
 	}
 	for i := 0; i < length; i++ {
-		resolved := style.Resolve(r.Frame.At(x, y+i).Style)
-		r.Frame.Set(x, y+i, grid.Cell{Ch: ch, Style: resolved})
+		r.put(x, y+i, grid.Cell{Ch: ch, Style: style})
 	}
 }
 
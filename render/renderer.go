@@ -10,6 +10,14 @@ import (
 type Renderer struct {
 	Frame  *grid.Frame
 	camera camera.Camera
+	// Sampling is used when a zoomed camera shrinks the world.
+	Sampling Sampling
+
+	// origin offsets screen coordinates and clip bounds drawing; both are
+	// set by Viewport.
+	originX int
+	originY int
+	clip    *Rect
 }
 
 func NewRenderer(frame *grid.Frame) *Renderer {
@@ -32,59 +40,79 @@ func (r *Renderer) WithCamera(cam camera.Camera) *Renderer {
 	if r == nil {
 		return nil
 	}
-	return &Renderer{Frame: r.Frame, camera: cam}
+	out := *r
+	out.camera = cam
+	return &out
 }
 
 func (r *Renderer) Screen() *Renderer {
 	return r.WithCamera(nil)
 }
 
+// Clear resets the frame, or only the viewport when drawing into one.
 func (r *Renderer) Clear() {
-	r.Frame.ClearAll()
+	if r.clip == nil {
+		r.Frame.ClearAll()
+		return
+	}
+	for y := r.clip.Y; y < r.clip.Y+r.clip.H; y++ {
+		for x := r.clip.X; x < r.clip.X+r.clip.W; x++ {
+			r.Frame.Set(x, y, r.Frame.Clear)
+		}
+	}
 }
 
 func (r *Renderer) DrawSprite(x, y int, sprite *Sprite) {
+	r.DrawSpriteAt(float64(x), float64(y), sprite)
+}
+
+// DrawSpriteAt is like DrawSprite for fractional world positions; the
+// position is rounded down after the camera transform. Zoomed cameras
+// (camera.Zoomer) scale the sprite, sampling cells according to r.Sampling.
+func (r *Renderer) DrawSpriteAt(x, y float64, sprite *Sprite) {
 	if r == nil || r.Frame == nil || sprite == nil {
 		return
 	}
 	if r.camera != nil {
-		if !r.camera.Visible(float64(x), float64(y), sprite.W, sprite.H) {
+		if !r.camera.Visible(x, y, sprite.W, sprite.H) {
 			return
 		}
-		wx, wy := r.camera.WorldToScreen(float64(x), float64(y))
-		x = int(math.Floor(wx))
-		y = int(math.Floor(wy))
+		if r.zoom() != 1 {
+			r.drawSpriteZoomed(x, y, sprite)
+			return
+		}
+		x, y = r.camera.WorldToScreen(x, y)
 	}
+	sx := int(math.Floor(x)) + r.originX
+	sy := int(math.Floor(y)) + r.originY
 	for row := 0; row < sprite.H; row++ {
 		for col := 0; col < sprite.W; col++ {
-			cell := sprite.cellAt(col, row)
-			if cell.Skip {
-				cell.Ch = ' '
-				cell.Style = cell.Style.Resolve(r.Frame.At(x+col, y+row).Style)
-				r.Frame.Set(x+col, y+row, cell)
-				continue
-			}
-			if cell.Ch == 0 {
-				continue
-			}
-			if sprite.Transparent != 0 && cell.Ch == sprite.Transparent {
-				continue
-			}
-			cell.Style = cell.Style.Resolve(r.Frame.At(x+col, y+row).Style)
-			r.Frame.Set(x+col, y+row, cell)
+			r.putSpriteCell(sx+col, sy+row, sprite, sprite.cellAt(col, row))
 		}
 	}
 }
 
+// putSpriteCell draws one sprite cell, skipping empty and transparent cells.
+func (r *Renderer) putSpriteCell(x, y int, sprite *Sprite, cell grid.Cell) {
+	if cell.Skip {
+		cell.Ch = ' '
+		r.put(x, y, cell)
+		return
+	}
+	if cell.Ch == 0 {
+		return
+	}
+	if sprite.Transparent != 0 && cell.Ch == sprite.Transparent {
+		return
+	}
+	r.put(x, y, cell)
+}
+
 func (r *Renderer) DrawText(x, y int, text string, style grid.Style) {
 	if r == nil || r.Frame == nil {
 		return
 	}
-	if r.camera != nil {
-		wx, wy := r.camera.WorldToScreen(float64(x), float64(y))
-		x = int(math.Floor(wx))
-		y = int(math.Floor(wy))
-	}
+	x, y = r.project(x, y)
 	cx := x
 	for _, ch := range text {
 		if ch == '\n' {
@@ -92,8 +120,42 @@ func (r *Renderer) DrawText(x, y int, text string, style grid.Style) {
 			cx = x
 			continue
 		}
-		resolved := style.Resolve(r.Frame.At(cx, y).Style)
-		r.Frame.Set(cx, y, grid.Cell{Ch: ch, Style: resolved})
+		r.put(cx, y, grid.Cell{Ch: ch, Style: style})
 		cx++
 	}
 }
+
+// DrawCell draws a single cell, resolving its style against what is below.
+func (r *Renderer) DrawCell(x, y int, cell grid.Cell) {
+	if r == nil || r.Frame == nil {
+		return
+	}
+	if r.camera != nil && r.zoom() != 1 {
+		r.DrawSprite(x, y, &Sprite{W: 1, H: 1, Cells: []grid.Cell{cell}})
+		return
+	}
+	if r.camera != nil && !r.camera.Visible(float64(x), float64(y), 1, 1) {
+		return
+	}
+	x, y = r.project(x, y)
+	r.put(x, y, cell)
+}
+
+// project maps x,y through the camera, if any, into frame coordinates.
+func (r *Renderer) project(x, y int) (int, int) {
+	if r.camera != nil {
+		wx, wy := r.camera.WorldToScreen(float64(x), float64(y))
+		x = int(math.Floor(wx))
+		y = int(math.Floor(wy))
+	}
+	return x + r.originX, y + r.originY
+}
+
+// put writes a frame cell, resolving its style and honoring the clip rect.
+func (r *Renderer) put(x, y int, cell grid.Cell) {
+	if r.clip != nil && !r.clip.Contains(x, y) {
+		return
+	}
+	cell.Style = cell.Style.Resolve(r.Frame.At(x, y).Style)
+	r.Frame.Set(x, y, cell)
+}
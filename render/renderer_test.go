@@ -0,0 +1,77 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/dgrundel/glif/camera"
+	"github.com/dgrundel/glif/grid"
+)
+
+// TestViewport verifies drawing is offset into the viewport, clipped to it and uses its camera.
+func TestViewport(t *testing.T) {
+	frame := grid.NewFrame(10, 4, grid.Cell{Ch: '.'})
+	r := NewRenderer(frame)
+	cam := camera.NewBasic()
+	v := NewViewport(5, 1, 3, 2, cam)
+	cam.Set(10, 0)
+
+	vr := r.Viewport(v)
+	vr.DrawText(8, 0, "abcdef", grid.Style{})
+	vr.Screen().DrawText(0, 1, "xyz!", grid.Style{})
+
+	want := []string{
+		"..........",
+		".....cde..",
+		".....xyz..",
+		"..........",
+	}
+	for y, row := range want {
+		for x, ch := range row {
+			if got := frame.At(x, y).Ch; got != ch {
+				t.Fatalf("cell %d,%d=%q want %q", x, y, got, ch)
+			}
+		}
+	}
+
+	vr.Clear()
+	if frame.At(6, 1).Ch != '.' || frame.At(5, 2).Ch != '.' {
+		t.Fatalf("viewport not cleared")
+	}
+}
+
+// TestZoom verifies zooming in repeats cells and zooming out samples them.
+func TestZoom(t *testing.T) {
+	sprite := &Sprite{W: 4, H: 1, Cells: []grid.Cell{{Ch: 'a'}, {Ch: ' '}, {Ch: 'b'}, {Ch: 'b'}}}
+	cam := camera.NewBasic()
+	cam.SetViewport(8, 2)
+
+	row := func(frame *grid.Frame, y int) string {
+		out := make([]rune, frame.W)
+		for x := range out {
+			out[x] = frame.At(x, y).Ch
+		}
+		return string(out)
+	}
+
+	cam.SetZoom(2)
+	cam.Set(0, 0)
+	frame := grid.NewFrame(8, 2, grid.Cell{Ch: '.'})
+	NewRenderer(frame).WithCamera(cam).DrawSprite(0, 0, sprite)
+	if got := row(frame, 0); got != "aa  bbbb" || row(frame, 1) != got {
+		t.Fatalf("zoom 2 rows=%q,%q", got, row(frame, 1))
+	}
+
+	cam.SetZoom(0.5)
+	cam.Set(0, 0)
+	frame = grid.NewFrame(8, 2, grid.Cell{Ch: '.'})
+	r := NewRenderer(frame).WithCamera(cam)
+	r.DrawSprite(0, 0, sprite)
+	if got := row(frame, 0); got != " b......" {
+		t.Fatalf("nearest row=%q", got)
+	}
+	r.Sampling = SampleMajority
+	r.DrawSprite(0, 0, sprite)
+	if got := row(frame, 0); got != "ab......" {
+		t.Fatalf("majority row=%q", got)
+	}
+}
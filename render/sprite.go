@@ -14,3 +14,46 @@ type Sprite struct {
 func (s *Sprite) cellAt(x, y int) grid.Cell {
 	return s.Cells[y*s.W+x]
 }
+
+// Restyle returns a copy of s with fn applied to the style of every cell.
+func (s *Sprite) Restyle(fn func(grid.Style) grid.Style) *Sprite {
+	if s == nil {
+		return nil
+	}
+	out := *s
+	out.Cells = make([]grid.Cell, len(s.Cells))
+	for i, cell := range s.Cells {
+		cell.Style = fn(cell.Style)
+		out.Cells[i] = cell
+	}
+	return &out
+}
+
+// Summary returns one cell that stands for the whole sprite: its most common
+// visible glyph, styled like its first occurrence. Sprites with no visible
+// glyph summarize to a blank cell with the first cell's style.
+func (s *Sprite) Summary() grid.Cell {
+	if s == nil || len(s.Cells) == 0 {
+		return grid.Cell{Ch: ' '}
+	}
+	counts := map[rune]int{}
+	first := map[rune]grid.Cell{}
+	best := rune(0)
+	for _, cell := range s.Cells {
+		ch := cell.Ch
+		if cell.Skip || ch == 0 || ch == ' ' || (s.Transparent != 0 && ch == s.Transparent) {
+			continue
+		}
+		counts[ch]++
+		if _, ok := first[ch]; !ok {
+			first[ch] = cell
+		}
+		if best == 0 || counts[ch] > counts[best] {
+			best = ch
+		}
+	}
+	if best == 0 {
+		return grid.Cell{Ch: ' ', Style: s.Cells[0].Style}
+	}
+	return first[best]
+}
@@ -0,0 +1,67 @@
+package render
+
+import "github.com/dgrundel/glif/camera"
+
+// Rect is a screen rectangle in cells.
+type Rect struct {
+	X int
+	Y int
+	W int
+	H int
+}
+
+func (r Rect) Contains(x, y int) bool {
+	return x >= r.X && y >= r.Y && x < r.X+r.W && y < r.Y+r.H
+}
+
+// Intersect returns the overlap of r and o, which may be empty.
+func (r Rect) Intersect(o Rect) Rect {
+	x0 := max(r.X, o.X)
+	y0 := max(r.Y, o.Y)
+	x1 := min(r.X+r.W, o.X+o.W)
+	y1 := min(r.Y+r.H, o.Y+o.H)
+	return Rect{X: x0, Y: y0, W: max(0, x1-x0), H: max(0, y1-y0)}
+}
+
+// Viewport binds a camera to a screen rectangle, e.g. one half of a
+// split screen or a minimap in a corner.
+type Viewport struct {
+	Rect
+	Camera camera.Camera
+}
+
+// NewViewport creates a viewport and sizes cam to match it.
+func NewViewport(x, y, w, h int, cam camera.Camera) *Viewport {
+	v := &Viewport{Camera: cam}
+	v.SetRect(x, y, w, h)
+	return v
+}
+
+// SetRect moves and resizes the viewport, updating the camera's viewport
+// size. Call it from Resize.
+func (v *Viewport) SetRect(x, y, w, h int) {
+	v.Rect = Rect{X: x, Y: y, W: w, H: h}
+	if v.Camera != nil {
+		v.Camera.SetViewport(w, h)
+	}
+}
+
+// Viewport returns a renderer that draws into v: coordinates are relative
+// to the viewport's top-left, the viewport's camera is used, and nothing is
+// drawn outside it. Screen() on the result keeps the offset and clipping, so
+// per-viewport HUDs can use viewport-relative screen coordinates.
+func (r *Renderer) Viewport(v *Viewport) *Renderer {
+	if r == nil || v == nil {
+		return r
+	}
+	out := *r
+	out.camera = v.Camera
+	out.originX += v.X
+	out.originY += v.Y
+	clip := Rect{X: out.originX, Y: out.originY, W: v.W, H: v.H}
+	if r.clip != nil {
+		clip = clip.Intersect(*r.clip)
+	}
+	out.clip = &clip
+	return &out
+}
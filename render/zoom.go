@@ -0,0 +1,111 @@
+package render
+
+import (
+	"math"
+
+	"github.com/dgrundel/glif/camera"
+	"github.com/dgrundel/glif/grid"
+)
+
+// Sampling picks which world cell a zoomed-out screen cell shows.
+type Sampling int
+
+const (
+	// SampleNearest shows the world cell nearest the screen cell's center.
+	SampleNearest Sampling = iota
+	// SampleMajority shows the most common glyph among the covered cells,
+	// preferring anything over blanks, so thin details survive zooming out.
+	SampleMajority
+)
+
+// zoom returns the camera's zoom, or 1.
+func (r *Renderer) zoom() float64 {
+	if z, ok := r.camera.(camera.Zoomer); ok {
+		if v := z.Zoom(); v > 0 {
+			return v
+		}
+	}
+	return 1
+}
+
+// drawSpriteZoomed draws a sprite through a scaling camera. Each screen
+// cell the sprite covers is mapped back to the world cells beneath it:
+// zooming in repeats cells, zooming out samples them.
+func (r *Renderer) drawSpriteZoomed(x, y float64, sprite *Sprite) {
+	sx0, sy0 := r.camera.WorldToScreen(x, y)
+	sx1, sy1 := r.camera.WorldToScreen(x+float64(sprite.W), y+float64(sprite.H))
+	for sy := int(math.Floor(sy0)); sy < int(math.Ceil(sy1)); sy++ {
+		for sx := int(math.Floor(sx0)); sx < int(math.Ceil(sx1)); sx++ {
+			wx0, wy0 := r.camera.ScreenToWorld(float64(sx), float64(sy))
+			wx1, wy1 := r.camera.ScreenToWorld(float64(sx+1), float64(sy+1))
+			var cell grid.Cell
+			var ok bool
+			if r.Sampling == SampleMajority {
+				cell, ok = majorityCell(sprite, wx0-x, wy0-y, wx1-x, wy1-y)
+			} else {
+				cell, ok = nearestCell(sprite, wx0-x, wy0-y, wx1-x, wy1-y)
+			}
+			if ok {
+				r.putSpriteCell(sx+r.originX, sy+r.originY, sprite, cell)
+			}
+		}
+	}
+}
+
+// nearestCell picks the sprite cell in the local area [x0,x1) x [y0,y1)
+// closest to its center.
+func nearestCell(sprite *Sprite, x0, y0, x1, y1 float64) (grid.Cell, bool) {
+	c0, r0, c1, r1 := coveredCells(sprite, x0, y0, x1, y1)
+	if c0 >= c1 || r0 >= r1 {
+		return grid.Cell{}, false
+	}
+	col := min(max(int(math.Floor((x0+x1)/2)), c0), c1-1)
+	row := min(max(int(math.Floor((y0+y1)/2)), r0), r1-1)
+	return sprite.cellAt(col, row), true
+}
+
+// coveredCells returns the sprite columns [c0,c1) and rows [r0,r1) that
+// overlap the local area [x0,x1) x [y0,y1).
+func coveredCells(sprite *Sprite, x0, y0, x1, y1 float64) (int, int, int, int) {
+	c0 := max(0, int(math.Floor(x0)))
+	r0 := max(0, int(math.Floor(y0)))
+	c1 := min(sprite.W, int(math.Ceil(x1)))
+	r1 := min(sprite.H, int(math.Ceil(y1)))
+	return c0, r0, c1, r1
+}
+
+// majorityCell picks the most common visible glyph among the sprite cells in
+// the local area [x0,x1) x [y0,y1). Blank cells only win when nothing else is
+// visible; ties go to the first glyph found in row-major order.
+func majorityCell(sprite *Sprite, x0, y0, x1, y1 float64) (grid.Cell, bool) {
+	c0, r0, c1, r1 := coveredCells(sprite, x0, y0, x1, y1)
+	var counts map[rune]int
+	var best, blank grid.Cell
+	bestN := 0
+	haveBlank := false
+	for row := r0; row < r1; row++ {
+		for col := c0; col < c1; col++ {
+			cell := sprite.cellAt(col, row)
+			if cell.Ch == 0 || (sprite.Transparent != 0 && cell.Ch == sprite.Transparent) {
+				continue
+			}
+			if cell.Skip || cell.Ch == ' ' {
+				if !haveBlank {
+					blank, haveBlank = cell, true
+				}
+				continue
+			}
+			if counts == nil {
+				counts = map[rune]int{}
+			}
+			counts[cell.Ch]++
+			if n := counts[cell.Ch]; n > bestN {
+				best, bestN = cell, n
+			}
+		}
+	}
+	if bestN > 0 {
+		return best, true
+	}
+	return blank, haveBlank
+}
@@ -0,0 +1,81 @@
+package tilemap
+
+import (
+	"math"
+
+	"github.com/dgrundel/glif/render"
+)
+
+// TileAnimation animates every instance of a tile id from the map clock.
+// When Scatter is true each cell starts on a different frame based on its
+// position so large areas don't pulse in lockstep.
+type TileAnimation struct {
+	Anim    *render.Animation
+	FPS     float64
+	Scatter bool
+}
+
+// Update advances the clock shared by all animated tiles.
+func (m *Map) Update(dt float64) {
+	if m == nil {
+		return
+	}
+	m.clock += dt
+}
+
+// SetPhase offsets the animation of the tile at x,y by the given seconds.
+func (m *Map) SetPhase(x, y int, seconds float64) {
+	if m == nil || !m.InBounds(x, y) {
+		return
+	}
+	if m.phases == nil {
+		m.phases = map[int]float64{}
+	}
+	if seconds == 0 {
+		delete(m.phases, y*m.W+x)
+		return
+	}
+	m.phases[y*m.W+x] = seconds
+}
+
+// Sprite returns the sprite drawn for the tile at x,y, resolving autotile
+// variants and animated tiles to their current frame. It returns nil for
+// empty cells.
+func (m *Map) Sprite(x, y int) *render.Sprite {
+	if m == nil || !m.InBounds(x, y) {
+		return nil
+	}
+	id := m.At(x, y)
+	if id == m.Empty {
+		return nil
+	}
+	if variant := m.autotileSprite(id, x, y); variant != nil {
+		return variant
+	}
+	if ta := m.Animations[id]; ta != nil {
+		if frame := m.animFrame(ta, x, y); frame != nil {
+			return frame
+		}
+	}
+	return m.Tileset[id]
+}
+
+func (m *Map) animFrame(ta *TileAnimation, x, y int) *render.Sprite {
+	if ta.Anim == nil || len(ta.Anim.Frames) == 0 {
+		return nil
+	}
+	fps := ta.FPS
+	if fps <= 0 {
+		fps = 8
+	}
+	n := len(ta.Anim.Frames)
+	index := int(math.Floor((m.clock + m.phases[y*m.W+x]) * fps))
+	if ta.Scatter {
+		index += int(uint32(x*73856093^y*19349663) % uint32(n))
+	}
+	index %= n
+	if index < 0 {
+		index += n
+	}
+	return ta.Anim.Frames[index]
+}
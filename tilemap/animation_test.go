@@ -0,0 +1,123 @@
+package tilemap
+
+import (
+	"testing"
+
+	"github.com/dgrundel/glif/grid"
+	"github.com/dgrundel/glif/render"
+)
+
+// animatedMap fills a w x h map with a three-frame animated tile.
+func animatedMap(w, h int, fps float64, scatter bool) (*Map, []*render.Sprite) {
+	frames := make([]*render.Sprite, 3)
+	for i := range frames {
+		frames[i] = &render.Sprite{W: 1, H: 1, Cells: []grid.Cell{{Ch: rune('0' + i)}}}
+	}
+	m := New(w, h, 1, 1, 0)
+	m.Tileset[1] = frames[0]
+	m.Animations[1] = &TileAnimation{Anim: &render.Animation{Base: frames[0], Frames: frames}, FPS: fps, Scatter: scatter}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			m.Set(x, y, 1)
+		}
+	}
+	return m, frames
+}
+
+// TestAnimationFrames verifies the frame follows the map clock, FPS and phase.
+func TestAnimationFrames(t *testing.T) {
+	m, frames := animatedMap(2, 1, 4, false)
+	steps := []struct {
+		dt   float64
+		want int
+	}{
+		{0, 0},
+		{0.25, 1},
+		{0.3, 2},
+		{0.25, 0}, // wraps after three frames
+	}
+	for i, s := range steps {
+		m.Update(s.dt)
+		if got := m.Sprite(0, 0); got != frames[s.want] {
+			t.Fatalf("step %d: frame %q want %d", i, got.Cells[0].Ch, s.want)
+		}
+	}
+
+	m.SetPhase(1, 0, 0.25)
+	if m.Sprite(1, 0) != frames[1] || m.Sprite(0, 0) != frames[0] {
+		t.Fatalf("phase should only shift cell 1,0")
+	}
+	m.SetPhase(1, 0, 0)
+	if m.Sprite(1, 0) != frames[0] {
+		t.Fatalf("clearing the phase should restore the shared frame")
+	}
+
+	slow, frames := animatedMap(1, 1, 0, false)
+	slow.Update(1.0 / 8)
+	if slow.Sprite(0, 0) != frames[1] {
+		t.Fatalf("FPS 0 should default to 8")
+	}
+}
+
+// TestAnimationScatter verifies scattered cells get stable per-cell offsets.
+func TestAnimationScatter(t *testing.T) {
+	m, frames := animatedMap(6, 6, 4, true)
+	index := func(s *render.Sprite) int {
+		for i, f := range frames {
+			if f == s {
+				return i
+			}
+		}
+		t.Fatalf("unknown frame %v", s)
+		return -1
+	}
+	before := map[[2]int]int{}
+	seen := map[int]bool{}
+	for y := 0; y < m.H; y++ {
+		for x := 0; x < m.W; x++ {
+			i := index(m.Sprite(x, y))
+			before[[2]int{x, y}] = i
+			seen[i] = true
+		}
+	}
+	if len(seen) < 2 {
+		t.Fatalf("scattered cells all start on the same frame")
+	}
+	m.Update(0.25)
+	for cell, i := range before {
+		if got := index(m.Sprite(cell[0], cell[1])); got != (i+1)%len(frames) {
+			t.Fatalf("cell %v: frame %d want %d", cell, got, (i+1)%len(frames))
+		}
+	}
+}
+
+// TestParseFPS verifies speeds are parsed and bad ones rejected.
+func TestParseFPS(t *testing.T) {
+	tests := []struct {
+		fields []string
+		fps    float64
+		rest   int
+		err    bool
+	}{
+		{fields: []string{"4fps", "liquid=true"}, fps: 4, rest: 1},
+		{fields: []string{"2.5fps"}, fps: 2.5},
+		{fields: []string{"speed=4fps"}, rest: 1},
+		{fields: nil},
+		{fields: []string{"0fps"}, err: true},
+		{fields: []string{"-1fps"}, err: true},
+		{fields: []string{"fastfps"}, err: true},
+		{fields: []string{"fps"}, err: true},
+	}
+	for _, tt := range tests {
+		fps, rest, err := parseFPS(tt.fields)
+		if tt.err {
+			if err == nil {
+				t.Errorf("parseFPS(%q) should fail", tt.fields)
+			}
+			continue
+		}
+		if err != nil || fps != tt.fps || len(rest) != tt.rest {
+			t.Errorf("parseFPS(%q)=%v,%q,%v want %v with %d left", tt.fields, fps, rest, err, tt.fps, tt.rest)
+		}
+	}
+}
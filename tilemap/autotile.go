@@ -0,0 +1,184 @@
+package tilemap
+
+import "github.com/dgrundel/glif/render"
+
+// Neighbor bits used to index autotile variants.
+// Four-neighbor (16 variant) tiles use N, E, S and W only:
+//
+//	N=1 E=2 S=4 W=8
+//
+// Blob (47 variant) tiles use all eight neighbors. Corner bits are only set
+// when both adjacent edges are set, which leaves 47 distinct masks:
+//
+//	N=1 NE=2 E=4 SE=8 S=16 SW=32 W=64 NW=128
+const (
+	Edge4N = 1
+	Edge4E = 2
+	Edge4S = 4
+	Edge4W = 8
+
+	BlobN  = 1
+	BlobNE = 2
+	BlobE  = 4
+	BlobSE = 8
+	BlobS  = 16
+	BlobSW = 32
+	BlobW  = 64
+	BlobNW = 128
+)
+
+// Autotile picks a tile's sprite from its neighbors. Variants are keyed by
+// neighbor mask; masks without a variant fall back to the base tile sprite.
+// Neighbors connect when they have the same id or the same `terrain`
+// property. Cells outside the map count as connected.
+type Autotile struct {
+	Blob     bool
+	Variants map[int]*render.Sprite
+}
+
+// Masks returns every neighbor mask the autotile can produce, in ascending order.
+func (a *Autotile) Masks() []int {
+	if !a.Blob {
+		out := make([]int, 16)
+		for i := range out {
+			out[i] = i
+		}
+		return out
+	}
+	return blobMasks
+}
+
+var blobMasks = func() []int {
+	var out []int
+	for m := 0; m < 256; m++ {
+		if reduceBlob(m) == m {
+			out = append(out, m)
+		}
+	}
+	return out
+}()
+
+// reduceBlob clears corner bits whose adjacent edges are not both set.
+func reduceBlob(m int) int {
+	if m&BlobN == 0 || m&BlobE == 0 {
+		m &^= BlobNE
+	}
+	if m&BlobS == 0 || m&BlobE == 0 {
+		m &^= BlobSE
+	}
+	if m&BlobS == 0 || m&BlobW == 0 {
+		m &^= BlobSW
+	}
+	if m&BlobN == 0 || m&BlobW == 0 {
+		m &^= BlobNW
+	}
+	return m
+}
+
+func (m *Map) connects(id, x, y int) bool {
+	if !m.InBounds(x, y) {
+		return true
+	}
+	other := m.At(x, y)
+	if other == id {
+		return true
+	}
+	terrain := m.TileProps[id].String("terrain", "")
+	return terrain != "" && m.TileProps[other].String("terrain", "") == terrain
+}
+
+// neighborMask computes the autotile mask for the tile at x,y.
+func (m *Map) neighborMask(a *Autotile, id, x, y int) int {
+	if !a.Blob {
+		mask := 0
+		if m.connects(id, x, y-1) {
+			mask |= Edge4N
+		}
+		if m.connects(id, x+1, y) {
+			mask |= Edge4E
+		}
+		if m.connects(id, x, y+1) {
+			mask |= Edge4S
+		}
+		if m.connects(id, x-1, y) {
+			mask |= Edge4W
+		}
+		return mask
+	}
+	bits := []struct {
+		dx, dy, bit int
+	}{
+		{0, -1, BlobN}, {1, -1, BlobNE}, {1, 0, BlobE}, {1, 1, BlobSE},
+		{0, 1, BlobS}, {-1, 1, BlobSW}, {-1, 0, BlobW}, {-1, -1, BlobNW},
+	}
+	mask := 0
+	for _, b := range bits {
+		if m.connects(id, x+b.dx, y+b.dy) {
+			mask |= b.bit
+		}
+	}
+	return reduceBlob(mask)
+}
+
+func (m *Map) updateVariant(x, y int) {
+	if !m.InBounds(x, y) {
+		return
+	}
+	idx := y*m.W + x
+	id := m.Tiles[idx]
+	a := m.Autotiles[id]
+	if a == nil {
+		m.variants[idx] = 0
+		return
+	}
+	m.variants[idx] = m.neighborMask(a, id, x, y)
+}
+
+// refreshAround re-evaluates autotile variants for x,y and its neighbors.
+func (m *Map) refreshAround(x, y int) {
+	if len(m.Autotiles) == 0 {
+		return
+	}
+	if len(m.variants) != len(m.Tiles) {
+		m.RefreshAutotiles()
+		return
+	}
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			m.updateVariant(x+dx, y+dy)
+		}
+	}
+}
+
+// RefreshAutotiles re-evaluates every autotile variant. Set does this
+// automatically for the changed tile's neighborhood; call it after editing
+// Tiles directly or changing Autotiles.
+func (m *Map) RefreshAutotiles() {
+	if m == nil {
+		return
+	}
+	if len(m.variants) != len(m.Tiles) {
+		m.variants = make([]int, len(m.Tiles))
+	}
+	for y := 0; y < m.H; y++ {
+		for x := 0; x < m.W; x++ {
+			m.updateVariant(x, y)
+		}
+	}
+}
+
+// Variant returns the autotile mask chosen for the tile at x,y.
+func (m *Map) Variant(x, y int) int {
+	if m == nil || !m.InBounds(x, y) || len(m.variants) != len(m.Tiles) {
+		return 0
+	}
+	return m.variants[y*m.W+x]
+}
+
+func (m *Map) autotileSprite(id, x, y int) *render.Sprite {
+	a := m.Autotiles[id]
+	if a == nil {
+		return nil
+	}
+	return a.Variants[m.Variant(x, y)]
+}
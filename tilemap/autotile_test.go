@@ -0,0 +1,109 @@
+package tilemap
+
+import "testing"
+
+// autotileMap builds a map where '#' is an autotiled tile and anything else
+// is empty.
+func autotileMap(blob bool, rows ...string) *Map {
+	m := New(len(rows[0]), len(rows), 1, 1, 0)
+	m.Autotiles[1] = &Autotile{Blob: blob}
+	for y, row := range rows {
+		for x, ch := range row {
+			if ch == '#' {
+				m.Set(x, y, 1)
+			}
+		}
+	}
+	return m
+}
+
+// TestAutotile16 verifies four-neighbor masks, with the map edge connecting.
+func TestAutotile16(t *testing.T) {
+	m := autotileMap(false,
+		".....",
+		"..#..",
+		".###.",
+		"..#..",
+		"....#",
+	)
+	tests := []struct {
+		x, y, want int
+	}{
+		{2, 2, Edge4N | Edge4E | Edge4S | Edge4W},
+		{2, 1, Edge4S},
+		{1, 2, Edge4E},
+		{3, 2, Edge4W},
+		{2, 3, Edge4N},
+		{4, 4, Edge4E | Edge4S}, // outside the map counts as connected
+	}
+	for _, tt := range tests {
+		if got := m.Variant(tt.x, tt.y); got != tt.want {
+			t.Errorf("Variant(%d,%d)=%d want %d", tt.x, tt.y, got, tt.want)
+		}
+	}
+	if n := len((&Autotile{}).Masks()); n != 16 {
+		t.Fatalf("16-tile masks=%d", n)
+	}
+}
+
+// TestAutotile47 verifies blob masks only keep corners backed by both edges.
+func TestAutotile47(t *testing.T) {
+	m := autotileMap(true,
+		".......",
+		".###...",
+		".###...",
+		".###...",
+		".....#.",
+		"......#",
+	)
+	tests := []struct {
+		name       string
+		x, y, want int
+	}{
+		{"surrounded", 2, 2, 255},
+		{"top left corner", 1, 1, BlobE | BlobSE | BlobS},
+		{"top edge", 2, 1, BlobE | BlobSE | BlobS | BlobSW | BlobW},
+		{"diagonal only", 5, 4, 0},
+		{"edge of map", 6, 5, BlobE | BlobSE | BlobS},
+	}
+	for _, tt := range tests {
+		if got := m.Variant(tt.x, tt.y); got != tt.want {
+			t.Errorf("%s: Variant(%d,%d)=%d want %d", tt.name, tt.x, tt.y, got, tt.want)
+		}
+	}
+	if n := len((&Autotile{Blob: true}).Masks()); n != 47 {
+		t.Fatalf("blob masks=%d want 47", n)
+	}
+}
+
+// TestAutotileSetRefreshesNeighbors verifies Set re-evaluates all eight neighbors.
+func TestAutotileSetRefreshesNeighbors(t *testing.T) {
+	m := autotileMap(true,
+		".....",
+		".###.",
+		".###.",
+		".###.",
+		".....",
+	)
+	m.Set(2, 2, 0)
+	want := map[[2]int]int{
+		{1, 1}: BlobE | BlobS,
+		{2, 1}: BlobE | BlobW,
+		{3, 1}: BlobS | BlobW,
+		{1, 2}: BlobN | BlobS,
+		{3, 2}: BlobN | BlobS,
+		{1, 3}: BlobN | BlobE,
+		{2, 3}: BlobE | BlobW,
+		{3, 3}: BlobN | BlobW,
+		{2, 2}: 0,
+	}
+	for cell, mask := range want {
+		if got := m.Variant(cell[0], cell[1]); got != mask {
+			t.Errorf("after Set, Variant%v=%d want %d", cell, got, mask)
+		}
+	}
+	m.Set(2, 2, 1)
+	if got := m.Variant(1, 1); got != BlobE|BlobSE|BlobS {
+		t.Fatalf("restoring the center: Variant(1,1)=%d", got)
+	}
+}
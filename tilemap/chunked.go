@@ -0,0 +1,320 @@
+package tilemap
+
+import (
+	"math"
+	"sort"
+
+	"github.com/dgrundel/glif/camera"
+	"github.com/dgrundel/glif/render"
+)
+
+// ChunkCoord identifies a chunk. Chunk 0,0 covers tiles [0,ChunkW) x [0,ChunkH).
+type ChunkCoord struct {
+	X int
+	Y int
+}
+
+// GenerateFunc fills a freshly created chunk. Tile 0,0 of chunk is world
+// tile coord.X*ChunkW, coord.Y*ChunkH.
+type GenerateFunc func(coord ChunkCoord, chunk *Map)
+
+// ChunkedMap is an unbounded tile map stored as fixed-size chunks that are
+// generated on demand and evicted when far from the view. Tile coordinates
+// may be negative. Only Set, Chunk and Stream generate chunks; reads treat
+// tiles in unloaded chunks as out of bounds. Chunks share the tileset,
+// properties, animations and autotiles of the ChunkedMap; autotile
+// neighbors are evaluated per chunk.
+type ChunkedMap struct {
+	ChunkW     int
+	ChunkH     int
+	TileW      int
+	TileH      int
+	Empty      int
+	Tileset    map[int]*render.Sprite
+	TileProps  map[int]Props
+	Animations map[int]*TileAnimation
+	Autotiles  map[int]*Autotile
+
+	Generate GenerateFunc
+	// OnEvict is called before a chunk is dropped, e.g. to persist edits.
+	OnEvict func(coord ChunkCoord, chunk *Map)
+
+	// LoadMargin is how many chunks beyond the view Stream keeps generated.
+	// Chunks further than LoadMargin+EvictMargin chunks from the view are evicted.
+	LoadMargin  int
+	EvictMargin int
+
+	chunks  map[ChunkCoord]*Map
+	clock   float64
+	version int
+}
+
+func NewChunked(chunkW, chunkH, tileW, tileH, empty int, gen GenerateFunc) *ChunkedMap {
+	if chunkW <= 0 {
+		chunkW = 16
+	}
+	if chunkH <= 0 {
+		chunkH = 16
+	}
+	if tileW <= 0 {
+		tileW = 1
+	}
+	if tileH <= 0 {
+		tileH = 1
+	}
+	return &ChunkedMap{
+		ChunkW:      chunkW,
+		ChunkH:      chunkH,
+		TileW:       tileW,
+		TileH:       tileH,
+		Empty:       empty,
+		Tileset:     map[int]*render.Sprite{},
+		TileProps:   map[int]Props{},
+		Animations:  map[int]*TileAnimation{},
+		Autotiles:   map[int]*Autotile{},
+		Generate:    gen,
+		LoadMargin:  1,
+		EvictMargin: 1,
+		chunks:      map[ChunkCoord]*Map{},
+	}
+}
+
+// ChunkOf returns the chunk holding tile x,y and the tile's coordinates inside it.
+func (c *ChunkedMap) ChunkOf(x, y int) (ChunkCoord, int, int) {
+	cx := floorDiv(x, c.ChunkW)
+	cy := floorDiv(y, c.ChunkH)
+	return ChunkCoord{X: cx, Y: cy}, x - cx*c.ChunkW, y - cy*c.ChunkH
+}
+
+// Loaded reports whether a chunk is currently in memory.
+func (c *ChunkedMap) Loaded(coord ChunkCoord) bool {
+	_, ok := c.chunks[coord]
+	return ok
+}
+
+// Chunks returns the coordinates of loaded chunks in row-major order.
+func (c *ChunkedMap) Chunks() []ChunkCoord {
+	out := make([]ChunkCoord, 0, len(c.chunks))
+	for coord := range c.chunks {
+		out = append(out, coord)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Y == out[j].Y {
+			return out[i].X < out[j].X
+		}
+		return out[i].Y < out[j].Y
+	})
+	return out
+}
+
+// Chunk returns the chunk at coord, generating it if needed.
+func (c *ChunkedMap) Chunk(coord ChunkCoord) *Map {
+	if m, ok := c.chunks[coord]; ok {
+		return m
+	}
+	m := New(c.ChunkW, c.ChunkH, c.TileW, c.TileH, c.Empty)
+	if c.Empty != 0 {
+		for i := range m.Tiles {
+			m.Tiles[i] = c.Empty
+		}
+	}
+	m.Tileset = c.Tileset
+	m.TileProps = c.TileProps
+	m.Animations = c.Animations
+	m.clock = c.clock
+	if c.Generate != nil {
+		c.Generate(coord, m)
+	}
+	m.Autotiles = c.Autotiles
+	m.RefreshAutotiles()
+	c.chunks[coord] = m
+	c.version++
+	return m
+}
+
+// Evict drops a loaded chunk, calling OnEvict first.
+func (c *ChunkedMap) Evict(coord ChunkCoord) {
+	m, ok := c.chunks[coord]
+	if !ok {
+		return
+	}
+	if c.OnEvict != nil {
+		c.OnEvict(coord, m)
+	}
+	delete(c.chunks, coord)
+	c.version++
+}
+
+// Stream generates every chunk within LoadMargin chunks of view (in world
+// cells) and evicts chunks more than LoadMargin+EvictMargin chunks away.
+// Call it each frame with the camera's visible area.
+func (c *ChunkedMap) Stream(view camera.Bounds) {
+	minC, maxC := c.chunkRange(view, c.LoadMargin)
+	for cy := minC.Y; cy <= maxC.Y; cy++ {
+		for cx := minC.X; cx <= maxC.X; cx++ {
+			c.Chunk(ChunkCoord{X: cx, Y: cy})
+		}
+	}
+	keepMin, keepMax := c.chunkRange(view, c.LoadMargin+c.EvictMargin)
+	for _, coord := range c.Chunks() {
+		if coord.X < keepMin.X || coord.X > keepMax.X || coord.Y < keepMin.Y || coord.Y > keepMax.Y {
+			c.Evict(coord)
+		}
+	}
+}
+
+func (c *ChunkedMap) chunkRange(view camera.Bounds, margin int) (ChunkCoord, ChunkCoord) {
+	cw := float64(c.ChunkW * c.TileW)
+	ch := float64(c.ChunkH * c.TileH)
+	minC := ChunkCoord{X: int(math.Floor(view.X / cw)), Y: int(math.Floor(view.Y / ch))}
+	maxC := ChunkCoord{X: int(math.Floor((view.X + view.W - 1) / cw)), Y: int(math.Floor((view.Y + view.H - 1) / ch))}
+	if view.W <= 0 {
+		maxC.X = minC.X
+	}
+	if view.H <= 0 {
+		maxC.Y = minC.Y
+	}
+	minC.X -= margin
+	minC.Y -= margin
+	maxC.X += margin
+	maxC.Y += margin
+	return minC, maxC
+}
+
+// InBounds reports whether the chunk holding x,y is loaded.
+func (c *ChunkedMap) InBounds(x, y int) bool {
+	coord, _, _ := c.ChunkOf(x, y)
+	return c.Loaded(coord)
+}
+
+// loaded returns the loaded chunk holding x,y and the tile's coordinates in
+// it, or nil.
+func (c *ChunkedMap) loaded(x, y int) (*Map, int, int) {
+	coord, lx, ly := c.ChunkOf(x, y)
+	return c.chunks[coord], lx, ly
+}
+
+// At returns the tile at x,y, or Empty when its chunk is not loaded.
+func (c *ChunkedMap) At(x, y int) int {
+	m, lx, ly := c.loaded(x, y)
+	if m == nil {
+		return c.Empty
+	}
+	return m.At(lx, ly)
+}
+
+// Set changes the tile at x,y, generating its chunk if needed.
+func (c *ChunkedMap) Set(x, y, id int) {
+	coord, lx, ly := c.ChunkOf(x, y)
+	m := c.Chunk(coord)
+	before := m.Version()
+	m.Set(lx, ly, id)
+	if m.Version() != before {
+		c.version++
+	}
+}
+
+// Props returns the properties of the tile at x,y, or nil when its chunk is
+// not loaded.
+func (c *ChunkedMap) Props(x, y int) Props {
+	m, lx, ly := c.loaded(x, y)
+	if m == nil {
+		return nil
+	}
+	return m.Props(lx, ly)
+}
+
+// Sprite returns the sprite drawn at x,y, or nil when its chunk is not loaded.
+func (c *ChunkedMap) Sprite(x, y int) *render.Sprite {
+	m, lx, ly := c.loaded(x, y)
+	if m == nil {
+		return nil
+	}
+	return m.Sprite(lx, ly)
+}
+
+func (c *ChunkedMap) TileSize() (int, int) {
+	return c.TileW, c.TileH
+}
+
+// Extent covers the loaded chunks, in tile coordinates.
+func (c *ChunkedMap) Extent() (x, y, w, h int) {
+	if len(c.chunks) == 0 {
+		return 0, 0, 0, 0
+	}
+	first := true
+	var minC, maxC ChunkCoord
+	for coord := range c.chunks {
+		if first {
+			minC, maxC = coord, coord
+			first = false
+			continue
+		}
+		minC.X = min(minC.X, coord.X)
+		minC.Y = min(minC.Y, coord.Y)
+		maxC.X = max(maxC.X, coord.X)
+		maxC.Y = max(maxC.Y, coord.Y)
+	}
+	return minC.X * c.ChunkW, minC.Y * c.ChunkH, (maxC.X - minC.X + 1) * c.ChunkW, (maxC.Y - minC.Y + 1) * c.ChunkH
+}
+
+// Version changes whenever a tile is Set or a chunk is loaded or evicted.
+func (c *ChunkedMap) Version() int {
+	return c.version
+}
+
+// Update advances the animation clock of every loaded chunk.
+func (c *ChunkedMap) Update(dt float64) {
+	c.clock += dt
+	for _, m := range c.chunks {
+		m.clock = c.clock
+	}
+}
+
+// WorldBounds returns the area covered by loaded chunks.
+func (c *ChunkedMap) WorldBounds() camera.Bounds {
+	x, y, w, h := c.Extent()
+	return camera.Bounds{
+		X: float64(x * c.TileW),
+		Y: float64(y * c.TileH),
+		W: float64(w * c.TileW),
+		H: float64(h * c.TileH),
+	}
+}
+
+// Draw renders the loaded chunks. Chunks are never generated while drawing.
+func (c *ChunkedMap) Draw(r *render.Renderer, worldX, worldY float64) {
+	c.DrawWith(r, worldX, worldY, nil)
+}
+
+// DrawWith is like Map.DrawWith; tx,ty passed to fn are world tile coordinates.
+func (c *ChunkedMap) DrawWith(r *render.Renderer, worldX, worldY float64, fn func(tx, ty int, sprite *render.Sprite) *render.Sprite) {
+	if c == nil || r == nil {
+		return
+	}
+	for _, coord := range c.Chunks() {
+		m := c.chunks[coord]
+		ox := coord.X * c.ChunkW
+		oy := coord.Y * c.ChunkH
+		cx := worldX + float64(ox*c.TileW)
+		cy := worldY + float64(oy*c.TileH)
+		if cam := r.Camera(); cam != nil && !cam.Visible(cx, cy, c.ChunkW*c.TileW, c.ChunkH*c.TileH) {
+			continue
+		}
+		var chunkFn func(tx, ty int, sprite *render.Sprite) *render.Sprite
+		if fn != nil {
+			chunkFn = func(tx, ty int, sprite *render.Sprite) *render.Sprite {
+				return fn(ox+tx, oy+ty, sprite)
+			}
+		}
+		m.DrawWith(r, cx, cy, chunkFn)
+	}
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,42 @@
+package tilemap
+
+import (
+	"testing"
+
+	"github.com/dgrundel/glif/camera"
+)
+
+// TestChunkedReadsDoNotGenerate verifies only Set, Chunk and Stream load chunks.
+func TestChunkedReadsDoNotGenerate(t *testing.T) {
+	generated := 0
+	c := NewChunked(4, 4, 1, 1, 0, func(coord ChunkCoord, chunk *Map) {
+		generated++
+		for i := range chunk.Tiles {
+			chunk.Tiles[i] = 1
+		}
+	})
+	c.TileProps[1] = Props{"solid": "true"}
+
+	if c.InBounds(100, -100) || c.At(100, -100) != 0 || c.Props(100, -100) != nil || c.Sprite(100, -100) != nil {
+		t.Fatalf("unloaded tiles should read as empty and out of bounds")
+	}
+	if generated != 0 || len(c.Chunks()) != 0 {
+		t.Fatalf("reads generated %d chunks", generated)
+	}
+
+	c.Set(-1, -1, 2)
+	if generated != 1 || !c.Loaded(ChunkCoord{X: -1, Y: -1}) {
+		t.Fatalf("Set should generate chunk -1,-1 (generated=%d)", generated)
+	}
+	if c.At(-1, -1) != 2 || c.At(-4, -4) != 1 || !c.Props(-2, -2).Bool("solid", false) {
+		t.Fatalf("loaded chunk reads wrong tiles")
+	}
+
+	c.Stream(camera.Bounds{X: 0, Y: 0, W: 4, H: 4})
+	if x, y, w, h := c.Extent(); x != -4 || y != -4 || w != 12 || h != 12 {
+		t.Fatalf("Extent=%d,%d %dx%d want -4,-4 12x12", x, y, w, h)
+	}
+	if b := c.WorldBounds(); b != (camera.Bounds{X: -4, Y: -4, W: 12, H: 12}) {
+		t.Fatalf("WorldBounds=%+v", b)
+	}
+}
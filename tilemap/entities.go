@@ -0,0 +1,121 @@
+package tilemap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Spawn is an object placed in a map, positioned in world cells relative to
+// the map's origin. Type selects the factory that creates it.
+type Spawn struct {
+	ID    int
+	Name  string
+	Type  string
+	Layer string
+	X     float64
+	Y     float64
+	W     float64
+	H     float64
+	Props Props
+}
+
+// entityRune declares that a rune in the `.map` spawns an entity. props
+// excludes the `under` and `name` keys.
+type entityRune struct {
+	typ   string
+	name  string
+	under rune
+	props Props
+}
+
+type entityFile struct {
+	runes  map[rune]entityRune
+	placed []Spawn
+}
+
+// LoadLevel loads a `.map`/`.tiles` pair plus an `.entities` file and returns
+// the map and its spawns. The `.entities` file has two kinds of lines:
+//
+//	# <rune> <type> [key=value ...]: every <rune> in the .map spawns <type>
+//	P player
+//	# <type> <x> <y> [key=value ...]: spawn at tile x,y (fractions allowed)
+//	slime 10 2.5 hp=3
+//
+// Cells holding an entity rune use the tile named by the `under` property
+// (a rune from `.tiles`), or stay empty; the map remembers the runes so Save
+// writes them back. A `name` property sets Spawn.Name. Neither key is kept
+// in Spawn.Props.
+func LoadLevel(mapPath, tilesPath, entitiesPath string) (*Map, []Spawn, error) {
+	ents, err := loadEntities(entitiesPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	m, spawns, err := loadMap(mapPath, tilesPath, ents)
+	if err != nil {
+		return nil, nil, err
+	}
+	m.EntitiesPath = entitiesPath
+	for _, s := range ents.placed {
+		s.X *= float64(m.TileW)
+		s.Y *= float64(m.TileH)
+		spawns = append(spawns, s)
+	}
+	return m, spawns, nil
+}
+
+func loadEntities(path string) (*entityFile, error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, err
+	}
+	ents := &entityFile{runes: map[rune]entityRune{}}
+	for i, line := range lines {
+		lineNo := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("invalid entities line %d: %q", lineNo, line)
+		}
+		if len(fields) >= 3 && isNumber(fields[1]) && isNumber(fields[2]) {
+			x, _ := strconv.ParseFloat(fields[1], 64)
+			y, _ := strconv.ParseFloat(fields[2], 64)
+			props, err := parseProps(fields[3:])
+			if err != nil {
+				return nil, fmt.Errorf("entities line %d: %w", lineNo, err)
+			}
+			name := props["name"]
+			delete(props, "name")
+			ents.placed = append(ents.placed, Spawn{Name: name, Type: fields[0], X: x, Y: y, Props: props})
+			continue
+		}
+		r := []rune(fields[0])
+		if len(r) != 1 {
+			return nil, fmt.Errorf("entities line %d: expected \"<rune> <type>\" or \"<type> <x> <y>\"", lineNo)
+		}
+		props, err := parseProps(fields[2:])
+		if err != nil {
+			return nil, fmt.Errorf("entities line %d: %w", lineNo, err)
+		}
+		er := entityRune{typ: fields[1], name: props["name"], under: ' ', props: props}
+		if under := props["under"]; under != "" {
+			u := []rune(under)
+			if len(u) != 1 {
+				return nil, fmt.Errorf("entities line %d: under must be a single rune", lineNo)
+			}
+			er.under = u[0]
+		}
+		delete(props, "under")
+		delete(props, "name")
+		ents.runes[r[0]] = er
+	}
+	return ents, nil
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
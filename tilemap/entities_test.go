@@ -0,0 +1,70 @@
+package tilemap
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// TestLoadLevel verifies rune and placed entities become spawns in world cells.
+func TestLoadLevel(t *testing.T) {
+	m, spawns, err := LoadLevel(
+		filepath.Join("testdata", "level.map"),
+		filepath.Join("testdata", "level.tiles"),
+		filepath.Join("testdata", "level.entities"),
+	)
+	if err != nil {
+		t.Fatalf("LoadLevel: %v", err)
+	}
+	if m.At(1, 0) != m.At(1, 1) || m.At(1, 0) == m.At(0, 0) {
+		t.Fatalf("rune cell should use the under tile, tiles=%v", m.Tiles)
+	}
+	if len(spawns) != 2 {
+		t.Fatalf("spawns=%v", spawns)
+	}
+	player := spawns[0]
+	if player.Type != "player" || player.X != 2 || player.Y != 0 || player.Props.Int("hp", 0) != 5 {
+		t.Fatalf("player=%+v", player)
+	}
+	coin := spawns[1]
+	if coin.Type != "coin" || coin.Name != "bonus" || coin.X != 3 || coin.Y != 1 {
+		t.Fatalf("coin=%+v", coin)
+	}
+}
+
+// TestLoadLevelSaveRoundTrip verifies Save writes entity runes back so the
+// level reloads with the same tiles and spawns.
+func TestLoadLevelSaveRoundTrip(t *testing.T) {
+	entities := filepath.Join("testdata", "level.entities")
+	m, spawns, err := LoadLevel(filepath.Join("testdata", "level.map"), filepath.Join("testdata", "level.tiles"), entities)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range spawns {
+		if s.Props.Has("under") || s.Props.Has("name") {
+			t.Fatalf("definition keys leaked into %s props: %v", s.Type, s.Props)
+		}
+	}
+
+	dir := t.TempDir()
+	mapPath := filepath.Join(dir, "level.map")
+	if err := m.Save(mapPath, filepath.Join(dir, "level.tiles")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	data, err := os.ReadFile(mapPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "wPw\nw.w\n" {
+		t.Fatalf("saved map=%q", data)
+	}
+	got, again, err := LoadLevel(mapPath, filepath.Join(dir, "level.tiles"), entities)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if !slices.Equal(got.Tiles, m.Tiles) || !reflect.DeepEqual(again, spawns) {
+		t.Fatalf("reloaded tiles=%v spawns=%+v, want %v %+v", got.Tiles, again, m.Tiles, spawns)
+	}
+}
@@ -3,17 +3,51 @@ package tilemap
 import (
 	"bufio"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/dgrundel/glif/assets"
 	"github.com/dgrundel/glif/render"
 )
 
+// TilesetMapping records how a map's runes and `.tiles` lines map to tile ids
+// so the map can be written back with Save.
 type TilesetMapping struct {
-	IDs map[rune]int
-	Map *Map
+	IDs  map[rune]int
+	Defs []TileDef
+	Map  *Map
+}
+
+// TileDef is one line of a `.tiles` file. Sprite is the base path as written,
+// relative to the tiles file, or "empty" for tiles without a sprite.
+type TileDef struct {
+	Key       rune
+	ID        int
+	Sprite    string
+	Animation string
+	FPS       float64
+	Props     Props
+
+	base string
+}
+
+// Add declares a tile so maps using it can be saved. It replaces any
+// existing definition for the same key.
+func (t *TilesetMapping) Add(def TileDef) {
+	if t.IDs == nil {
+		t.IDs = map[rune]int{}
+	}
+	for i, d := range t.Defs {
+		if d.Key == def.Key {
+			t.Defs = append(t.Defs[:i], t.Defs[i+1:]...)
+			break
+		}
+	}
+	t.IDs[def.Key] = def.ID
+	t.Defs = append(t.Defs, def)
 }
 
 func LoadFromFiles(mapPath, tilesPath string) (*Map, error) {
@@ -21,11 +55,33 @@ func LoadFromFiles(mapPath, tilesPath string) (*Map, error) {
 	return m, err
 }
 
+type tileset struct {
+	ids     map[rune]int
+	sprites map[int]*render.Sprite
+	props   map[int]Props
+	anims   map[int]*TileAnimation
+	autos   map[int]*Autotile
+	defs    []TileDef
+	tileW   int
+	tileH   int
+}
+
 func loadMapWithMapping(mapPath, tilesPath string) (*Map, map[rune]int, error) {
-	mappings, tileset, tileW, tileH, err := loadTileset(tilesPath)
+	m, _, err := loadMap(mapPath, tilesPath, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, m.Mapping.IDs, nil
+}
+
+// loadMap loads a map, turning runes declared in ents into spawns.
+func loadMap(mapPath, tilesPath string, ents *entityFile) (*Map, []Spawn, error) {
+	ts, err := loadTileset(tilesPath)
 	if err != nil {
 		return nil, nil, err
 	}
+	mappings := ts.ids
+	tileW, tileH := ts.tileW, ts.tileH
 
 	lines, err := readLines(mapPath)
 	if err != nil {
@@ -33,16 +89,36 @@ func loadMapWithMapping(mapPath, tilesPath string) (*Map, map[rune]int, error) {
 	}
 	grid := toRuneLines(lines)
 	w, h := dims(grid)
+	m := New(w, h, tileW, tileH, 0)
+	m.Mapping = &TilesetMapping{IDs: mappings, Defs: ts.defs, Map: m}
+	m.MapPath, m.TilesPath = mapPath, tilesPath
 	if w == 0 || h == 0 {
-		return New(0, 0, tileW, tileH, 0), mappings, nil
+		return m, nil, nil
 	}
-
-	m := New(w, h, tileW, tileH, 0)
-	m.Tileset = tileset
+	m.Tileset = ts.sprites
+	m.TileProps = ts.props
+	m.Animations = ts.anims
+	var spawns []Spawn
 	for y := 0; y < h; y++ {
 		line := grid[y]
 		for x := 0; x < w; x++ {
 			ch := runeAt(line, x)
+			if ents != nil {
+				if er, ok := ents.runes[ch]; ok {
+					spawns = append(spawns, Spawn{
+						Name:  er.name,
+						Type:  er.typ,
+						X:     float64(x * tileW),
+						Y:     float64(y * tileH),
+						Props: maps.Clone(er.props),
+					})
+					if m.EntityRunes == nil {
+						m.EntityRunes = map[int]rune{}
+					}
+					m.EntityRunes[y*w+x] = ch
+					ch = er.under
+				}
+			}
 			if ch == ' ' {
 				continue
 			}
@@ -53,20 +129,27 @@ func loadMapWithMapping(mapPath, tilesPath string) (*Map, map[rune]int, error) {
 			m.Set(x, y, id)
 		}
 	}
-	return m, mappings, nil
+	// Autotiles are attached after filling so variants are computed once.
+	m.Autotiles = ts.autos
+	m.RefreshAutotiles()
+	return m, spawns, nil
 }
 
-func loadTileset(path string) (map[rune]int, map[int]*render.Sprite, int, int, error) {
+func loadTileset(path string) (*tileset, error) {
 	file, err := os.Open(path)
 	if err != nil {
-		return nil, nil, 1, 1, err
+		return nil, err
 	}
 	defer file.Close()
 
-	ids := map[rune]int{}
-	tileset := map[int]*render.Sprite{}
+	ts := &tileset{
+		ids:     map[rune]int{},
+		sprites: map[int]*render.Sprite{},
+		props:   map[int]Props{},
+		anims:   map[int]*TileAnimation{},
+		autos:   map[int]*Autotile{},
+	}
 	nextID := 1
-	var tileW, tileH int
 
 	scanner := bufio.NewScanner(file)
 	lineNo := 0
@@ -79,16 +162,32 @@ func loadTileset(path string) (map[rune]int, map[int]*render.Sprite, int, int, e
 		}
 		fields := strings.Fields(line)
 		if len(fields) < 2 {
-			return nil, nil, 1, 1, fmt.Errorf("invalid tiles line %d: %q", lineNo, line)
+			return nil, fmt.Errorf("invalid tiles line %d: %q", lineNo, line)
 		}
 		r := []rune(fields[0])
 		if len(r) != 1 {
-			return nil, nil, 1, 1, fmt.Errorf("tile key must be single rune on line %d", lineNo)
+			return nil, fmt.Errorf("tile key must be single rune on line %d", lineNo)
 		}
 		key := r[0]
-		name := fields[1]
+		name, animName, _ := strings.Cut(fields[1], "@")
+		fps, rest, err := parseFPS(fields[2:])
+		if err != nil {
+			return nil, fmt.Errorf("tiles line %d: %w", lineNo, err)
+		}
+		props, err := parseProps(rest)
+		if err != nil {
+			return nil, fmt.Errorf("tiles line %d: %w", lineNo, err)
+		}
 		if name == "empty" || name == "none" {
-			ids[key] = 0
+			// Every empty key shares id 0, so only one may declare props.
+			if props != nil && ts.props[0] != nil {
+				return nil, fmt.Errorf("tiles line %d: properties for empty tiles are already declared", lineNo)
+			}
+			ts.ids[key] = 0
+			if props != nil {
+				ts.props[0] = props
+			}
+			ts.defs = append(ts.defs, TileDef{Key: key, ID: 0, Sprite: name, Props: props})
 			continue
 		}
 		base := name
@@ -97,27 +196,113 @@ func loadTileset(path string) (map[rune]int, map[int]*render.Sprite, int, int, e
 		}
 		sprite, err := assets.LoadSprite(base)
 		if err != nil {
-			return nil, nil, 1, 1, fmt.Errorf("load sprite %q: %w", name, err)
+			return nil, fmt.Errorf("load sprite %q: %w", name, err)
+		}
+		if ts.tileW == 0 {
+			ts.tileW = sprite.W
+			ts.tileH = sprite.H
+		} else if sprite.W != ts.tileW || sprite.H != ts.tileH {
+			return nil, fmt.Errorf("sprite %q size %dx%d does not match tileset size %dx%d", name, sprite.W, sprite.H, ts.tileW, ts.tileH)
 		}
-		if tileW == 0 {
-			tileW = sprite.W
-			tileH = sprite.H
-		} else if sprite.W != tileW || sprite.H != tileH {
-			return nil, nil, 1, 1, fmt.Errorf("sprite %q size %dx%d does not match tileset size %dx%d", name, sprite.W, sprite.H, tileW, tileH)
+		ts.ids[key] = nextID
+		ts.defs = append(ts.defs, TileDef{Key: key, ID: nextID, Sprite: name, Animation: animName, FPS: fps, Props: props, base: base})
+		ts.sprites[nextID] = sprite
+		if props != nil {
+			ts.props[nextID] = props
+		}
+		if animName != "" {
+			anim, err := sprite.LoadAnimation(animName)
+			if err != nil {
+				return nil, fmt.Errorf("load animation %q for sprite %q: %w", animName, name, err)
+			}
+			ts.anims[nextID] = &TileAnimation{Anim: anim, FPS: fps, Scatter: props.String("phase", "") == "scatter"}
+		}
+		if props.Has("autotile") {
+			auto, err := loadAutotile(base, props.String("autotile", ""), ts.tileW, ts.tileH)
+			if err != nil {
+				return nil, fmt.Errorf("tiles line %d: %w", lineNo, err)
+			}
+			ts.autos[nextID] = auto
 		}
-		ids[key] = nextID
-		tileset[nextID] = sprite
 		nextID++
-		// Note: tileset entries are stored in Map after creation.
 	}
 	if err := scanner.Err(); err != nil {
-		return nil, nil, 1, 1, err
+		return nil, err
+	}
+	if ts.tileW == 0 {
+		ts.tileW = 1
+		ts.tileH = 1
+	}
+	return ts, nil
+}
+
+// loadAutotile loads the variant sprites `<base>-<mask>` for each neighbor
+// mask. kind is "16" (four neighbors) or "47" (blob). Missing variants are
+// allowed and fall back to the base sprite.
+func loadAutotile(base, kind string, tileW, tileH int) (*Autotile, error) {
+	auto := &Autotile{Variants: map[int]*render.Sprite{}}
+	switch kind {
+	case "16":
+	case "47":
+		auto.Blob = true
+	default:
+		return nil, fmt.Errorf("invalid autotile %q (expected 16 or 47)", kind)
 	}
-	if tileW == 0 {
-		tileW = 1
-		tileH = 1
+	for _, mask := range auto.Masks() {
+		variant := base + "-" + strconv.Itoa(mask)
+		if !fileExists(variant + ".sprite") {
+			continue
+		}
+		sprite, err := assets.LoadSprite(variant)
+		if err != nil {
+			return nil, fmt.Errorf("load autotile variant %q: %w", variant, err)
+		}
+		if sprite.W != tileW || sprite.H != tileH {
+			return nil, fmt.Errorf("autotile variant %q size %dx%d does not match tileset size %dx%d", variant, sprite.W, sprite.H, tileW, tileH)
+		}
+		auto.Variants[mask] = sprite
 	}
-	return ids, tileset, tileW, tileH, nil
+	return auto, nil
+}
+
+// parseFPS extracts an optional `<n>fps` field and returns the remaining fields.
+func parseFPS(fields []string) (float64, []string, error) {
+	fps := 0.0
+	rest := fields[:0:0]
+	for _, field := range fields {
+		num, ok := strings.CutSuffix(field, "fps")
+		if !ok || strings.Contains(field, "=") {
+			rest = append(rest, field)
+			continue
+		}
+		v, err := strconv.ParseFloat(num, 64)
+		if err != nil || v <= 0 {
+			return 0, nil, fmt.Errorf("invalid animation speed %q", field)
+		}
+		fps = v
+	}
+	return fps, rest, nil
+}
+
+// parseProps parses trailing `key=value` fields from a tiles line.
+func parseProps(fields []string) (Props, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	props := Props{}
+	for _, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid tile property %q (expected key=value)", field)
+		}
+		props[key] = value
+	}
+	return props, nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
 }
 
 func readLines(path string) ([]string, error) {
@@ -0,0 +1,32 @@
+package tilemap
+
+import (
+	"github.com/dgrundel/glif/grid"
+	"github.com/dgrundel/glif/render"
+)
+
+// DrawMinimap draws the map at one cell per tile with its top-left at x,y,
+// using each tile sprite's Summary. Coordinates go through r's camera, so a
+// minimap viewport can scroll over maps larger than itself.
+func (m *Map) DrawMinimap(r *render.Renderer, x, y int) {
+	if m == nil || r == nil {
+		return
+	}
+	if m.summary == nil {
+		m.summary = map[*render.Sprite]grid.Cell{}
+	}
+	for ty := 0; ty < m.H; ty++ {
+		for tx := 0; tx < m.W; tx++ {
+			sprite := m.Sprite(tx, ty)
+			if sprite == nil {
+				continue
+			}
+			cell, ok := m.summary[sprite]
+			if !ok {
+				cell = sprite.Summary()
+				m.summary[sprite] = cell
+			}
+			r.DrawCell(x+tx, y+ty, cell)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package tilemap
+
+import "strconv"
+
+// Props holds the key=value properties declared for a tile in a `.tiles` file.
+// Properties on an `empty` tile apply to every blank or unmapped cell.
+type Props map[string]string
+
+// Has reports whether key was declared.
+func (p Props) Has(key string) bool {
+	_, ok := p[key]
+	return ok
+}
+
+// String returns key's value, or def when it is not declared.
+func (p Props) String(key, def string) string {
+	if v, ok := p[key]; ok {
+		return v
+	}
+	return def
+}
+
+// Bool returns key parsed with strconv.ParseBool, or def when it is not
+// declared or does not parse.
+func (p Props) Bool(key string, def bool) bool {
+	v, ok := p[key]
+	if !ok {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return def
+	}
+	return b
+}
+
+// Int returns key parsed as a decimal integer, or def when it is not
+// declared or does not parse.
+func (p Props) Int(key string, def int) int {
+	v, ok := p[key]
+	if !ok {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// Float returns key parsed as a float64, or def when it is not declared or
+// does not parse.
+func (p Props) Float(key string, def float64) float64 {
+	v, ok := p[key]
+	if !ok {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return def
+	}
+	return f
+}
+
+// Props returns the properties of the tile at x,y. The result is nil for
+// out-of-bounds cells and tiles without properties.
+func (m *Map) Props(x, y int) Props {
+	if m == nil || !m.InBounds(x, y) {
+		return nil
+	}
+	return m.TileProps[m.At(x, y)]
+}
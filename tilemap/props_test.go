@@ -0,0 +1,40 @@
+package tilemap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestEmptyTileProps verifies empty-tile props cover blank cells and may
+// only be declared once.
+func TestEmptyTileProps(t *testing.T) {
+	dir := t.TempDir()
+	mapPath := filepath.Join(dir, "level.map")
+	tilesPath := filepath.Join(dir, "level.tiles")
+	if err := os.WriteFile(mapPath, []byte(". x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	write := func(tiles string) {
+		if err := os.WriteFile(tilesPath, []byte(tiles), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(". empty solid=true\nx empty\n")
+	m, err := LoadFromFiles(mapPath, tilesPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for x := 0; x < 3; x++ {
+		if !m.Props(x, 0).Bool("solid", false) {
+			t.Fatalf("cell %d should use the empty tile's props", x)
+		}
+	}
+
+	write(". empty solid=true\nx empty solid=false\n")
+	if _, err := LoadFromFiles(mapPath, tilesPath); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("second empty props should fail, got %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package tilemap
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Save writes the map back to a `.map`/`.tiles` pair using m.Mapping. Tile
+// ids are written as the first rune declared for them; empty cells without
+// a declared rune are written as spaces. Cells in m.EntityRunes are written
+// as their entity rune, so LoadLevel maps keep their spawns. Properties and
+// animation speeds are taken from the map, so runtime changes are persisted.
+// Sprite paths are rewritten relative to tilesPath. Both files are written
+// to temp files first, so an error leaves the old pair in place.
+func (m *Map) Save(mapPath, tilesPath string) error {
+	if m == nil {
+		return fmt.Errorf("map is nil")
+	}
+	if m.Mapping == nil {
+		return fmt.Errorf("map has no tileset mapping")
+	}
+	runes := map[int]rune{}
+	for _, def := range m.Mapping.Defs {
+		if _, ok := runes[def.ID]; !ok {
+			runes[def.ID] = def.Key
+		}
+	}
+
+	var mapText strings.Builder
+	for y := 0; y < m.H; y++ {
+		row := make([]rune, m.W)
+		for x := 0; x < m.W; x++ {
+			if r, ok := m.EntityRunes[y*m.W+x]; ok {
+				row[x] = r
+				continue
+			}
+			id := m.At(x, y)
+			ch, ok := runes[id]
+			if !ok {
+				if id != m.Empty {
+					return fmt.Errorf("tile id %d at %d,%d has no rune in the tileset mapping", id, x, y)
+				}
+				ch = ' '
+			}
+			row[x] = ch
+		}
+		mapText.WriteString(strings.TrimRight(string(row), " "))
+		mapText.WriteByte('\n')
+	}
+
+	tilesText, err := m.tilesText(filepath.Dir(tilesPath))
+	if err != nil {
+		return err
+	}
+	return writeFiles([]fileData{
+		{path: tilesPath, data: []byte(tilesText)},
+		{path: mapPath, data: []byte(mapText.String())},
+	})
+}
+
+type fileData struct {
+	path string
+	data []byte
+}
+
+// writeFiles writes every file to a temp file next to it before renaming
+// any into place, so a failed write leaves all the old files untouched.
+func writeFiles(files []fileData) error {
+	temps := make([]string, 0, len(files))
+	defer func() {
+		for _, tmp := range temps {
+			os.Remove(tmp)
+		}
+	}()
+	for _, f := range files {
+		tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp*")
+		if err != nil {
+			return err
+		}
+		temps = append(temps, tmp.Name())
+		_, err = tmp.Write(f.data)
+		if cerr := tmp.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil {
+			err = os.Chmod(tmp.Name(), 0o644)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for i, f := range files {
+		if err := os.Rename(temps[i], f.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Map) tilesText(dir string) (string, error) {
+	var b strings.Builder
+	b.WriteString("# key  sprite[@animation]  [fps]  [key=value ...]\n")
+	// Keys sharing an id, such as several empty keys, share its props, so
+	// only the first definition carries them, as in the rune map.
+	propsWritten := map[int]bool{}
+	for _, def := range m.Mapping.Defs {
+		fields := []string{string(def.Key)}
+		name := def.Sprite
+		if def.base != "" && !filepath.IsAbs(def.Sprite) {
+			absDir, err := filepath.Abs(dir)
+			if err != nil {
+				return "", err
+			}
+			absBase, err := filepath.Abs(def.base)
+			if err != nil {
+				return "", err
+			}
+			rel, err := filepath.Rel(absDir, absBase)
+			if err != nil {
+				return "", err
+			}
+			name = filepath.ToSlash(rel)
+		}
+		if ta := m.Animations[def.ID]; ta != nil && def.Animation != "" {
+			name += "@" + def.Animation
+			fields = append(fields, name)
+			if ta.FPS > 0 {
+				fields = append(fields, strconv.FormatFloat(ta.FPS, 'f', -1, 64)+"fps")
+			}
+		} else {
+			fields = append(fields, name)
+		}
+		var props Props
+		if !propsWritten[def.ID] {
+			props = m.TileProps[def.ID]
+			propsWritten[def.ID] = true
+		}
+		keys := make([]string, 0, len(props))
+		for k := range props {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fields = append(fields, k+"="+props[k])
+		}
+		b.WriteString(strings.Join(fields, " "))
+		b.WriteByte('\n')
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,83 @@
+package tilemap
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// TestSaveRoundTrip verifies a saved map loads back with the same tiles and
+// props, including tilesets where several keys are empty.
+func TestSaveRoundTrip(t *testing.T) {
+	t.Run("edited", func(t *testing.T) {
+		m, err := LoadFromFiles(filepath.Join("testdata", "room.map"), filepath.Join("testdata", "level.tiles"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		wall := m.Mapping.IDs['w']
+		m.Set(1, 1, wall)
+		m.Set(1, 0, 0)
+		m.TileProps[wall]["cost"] = "9"
+		dir := saveRoundTrip(t, m)
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 2 {
+			t.Fatalf("Save left temp files behind: %v", entries)
+		}
+	})
+
+	t.Run("two empty keys", func(t *testing.T) {
+		wallSprite, err := filepath.Abs(filepath.Join("testdata", "wall"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		dir := t.TempDir()
+		mapPath := filepath.Join(dir, "in.map")
+		tilesPath := filepath.Join(dir, "in.tiles")
+		if err := os.WriteFile(mapPath, []byte("w.,\n,.w\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		tiles := "w " + filepath.ToSlash(wallSprite) + "\n. empty solid=false\n, empty\n"
+		if err := os.WriteFile(tilesPath, []byte(tiles), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		m, err := LoadFromFiles(mapPath, tilesPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		saveRoundTrip(t, m)
+	})
+}
+
+// saveRoundTrip saves m to a temp dir, reloads it and checks the tiles,
+// props and sprites match. It returns the dir.
+func saveRoundTrip(t *testing.T, m *Map) string {
+	t.Helper()
+	dir := t.TempDir()
+	mapPath := filepath.Join(dir, "out.map")
+	tilesPath := filepath.Join(dir, "out.tiles")
+	if err := m.Save(mapPath, tilesPath); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	got, err := LoadFromFiles(mapPath, tilesPath)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if got.W != m.W || got.H != m.H || !slices.Equal(got.Tiles, m.Tiles) {
+		t.Fatalf("tiles=%v (%dx%d) want %v (%dx%d)", got.Tiles, got.W, got.H, m.Tiles, m.W, m.H)
+	}
+	for id, props := range m.TileProps {
+		if !maps.Equal(got.TileProps[id], props) {
+			t.Fatalf("props[%d]=%v want %v", id, got.TileProps[id], props)
+		}
+	}
+	if got.Sprite(0, 0) == nil {
+		t.Fatalf("sprites should resolve relative to the new tiles file")
+	}
+	return dir
+}
@@ -0,0 +1,14 @@
+<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" name="tiles" tilewidth="16" tileheight="8" tilecount="2" columns="2">
+ <tile id="0">
+  <properties>
+   <property name="sprite" value="wall"/>
+   <property name="solid" type="bool" value="true"/>
+  </properties>
+ </tile>
+ <tile id="1">
+  <properties>
+   <property name="cost" type="int" value="3"/>
+  </properties>
+ </tile>
+</tileset>
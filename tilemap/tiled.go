@@ -0,0 +1,180 @@
+package tilemap
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dgrundel/glif/assets"
+	"github.com/dgrundel/glif/render"
+)
+
+// TiledMap is a map imported from the Tiled editor.
+type TiledMap struct {
+	Layers []TiledLayer
+	Spawns []Spawn
+	Props  Props
+	TileW  int
+	TileH  int
+}
+
+// TiledLayer is one tile layer. All layers share the same tileset, where
+// tile ids are Tiled's global tile ids (0 is empty).
+type TiledLayer struct {
+	Name    string
+	Visible bool
+	Props   Props
+	Map     *Map
+}
+
+// Layer returns the map for the named tile layer, or nil.
+func (t *TiledMap) Layer(name string) *Map {
+	for _, l := range t.Layers {
+		if l.Name == name {
+			return l.Map
+		}
+	}
+	return nil
+}
+
+// SpawnsOf returns the spawns with the given type (Tiled's class).
+func (t *TiledMap) SpawnsOf(typ string) []Spawn {
+	var out []Spawn
+	for _, s := range t.Spawns {
+		if s.Type == typ {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// LoadTiled imports a Tiled map saved as JSON (.tmj/.json) or XML (.tmx).
+//
+// Each tile that should be drawn needs a custom `sprite` property holding a
+// glif sprite base path, relative to its tileset file. Other custom tile
+// properties become tile Props. Infinite maps, compressed layers other than
+// gzip/zlib, and tile flipping are not supported; flip flags are ignored.
+func LoadTiled(path string) (*TiledMap, error) {
+	var doc *tiledDoc
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tmx", ".xml":
+		doc, err = decodeTMX(path)
+	case ".tmj", ".json":
+		doc, err = decodeTMJ(path)
+	default:
+		return nil, fmt.Errorf("unknown Tiled map format %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc.build()
+}
+
+// tiledDoc is the format-independent view of a Tiled map.
+type tiledDoc struct {
+	width    int
+	height   int
+	tileW    int
+	tileH    int
+	infinite bool
+	props    Props
+	tilesets []tiledTileset
+	layers   []tiledLayer
+}
+
+type tiledTileset struct {
+	firstGID int
+	dir      string
+	tiles    map[int]Props
+}
+
+type tiledLayer struct {
+	object  bool
+	name    string
+	visible bool
+	props   Props
+	data    []uint32
+	objects []Spawn
+}
+
+// gidMask strips Tiled's flip and rotation flags from a global tile id.
+const gidMask = 0x0fffffff
+
+func (d *tiledDoc) build() (*TiledMap, error) {
+	if d.infinite {
+		return nil, fmt.Errorf("infinite Tiled maps are not supported")
+	}
+	if d.tileW <= 0 || d.tileH <= 0 {
+		return nil, fmt.Errorf("invalid Tiled tile size %dx%d", d.tileW, d.tileH)
+	}
+
+	sprites := map[int]*render.Sprite{}
+	props := map[int]Props{}
+	var tileW, tileH int
+	for _, ts := range d.tilesets {
+		ids := make([]int, 0, len(ts.tiles))
+		for id := range ts.tiles {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		for _, id := range ids {
+			tp := Props{}
+			for k, v := range ts.tiles[id] {
+				tp[k] = v
+			}
+			gid := ts.firstGID + id
+			if base := tp["sprite"]; base != "" {
+				if !filepath.IsAbs(base) {
+					base = filepath.Join(ts.dir, base)
+				}
+				sprite, err := assets.LoadSprite(base)
+				if err != nil {
+					return nil, fmt.Errorf("load sprite %q for tile %d: %w", tp["sprite"], gid, err)
+				}
+				if tileW == 0 {
+					tileW, tileH = sprite.W, sprite.H
+				} else if sprite.W != tileW || sprite.H != tileH {
+					return nil, fmt.Errorf("sprite %q size %dx%d does not match tileset size %dx%d", tp["sprite"], sprite.W, sprite.H, tileW, tileH)
+				}
+				sprites[gid] = sprite
+				delete(tp, "sprite")
+			}
+			if len(tp) > 0 {
+				props[gid] = tp
+			}
+		}
+	}
+	if tileW == 0 {
+		tileW, tileH = 1, 1
+	}
+
+	out := &TiledMap{Props: d.props, TileW: tileW, TileH: tileH}
+	scaleX := float64(tileW) / float64(d.tileW)
+	scaleY := float64(tileH) / float64(d.tileH)
+	for _, l := range d.layers {
+		if l.object {
+			for _, s := range l.objects {
+				s.Layer = l.name
+				s.X *= scaleX
+				s.Y *= scaleY
+				s.W *= scaleX
+				s.H *= scaleY
+				out.Spawns = append(out.Spawns, s)
+			}
+			continue
+		}
+		if len(l.data) != d.width*d.height {
+			return nil, fmt.Errorf("layer %q has %d tiles, want %d", l.name, len(l.data), d.width*d.height)
+		}
+		m := New(d.width, d.height, tileW, tileH, 0)
+		m.Tileset = sprites
+		m.TileProps = props
+		for i, gid := range l.data {
+			m.Tiles[i] = int(gid & gidMask)
+		}
+		out.Layers = append(out.Layers, TiledLayer{Name: l.name, Visible: l.visible, Props: l.props, Map: m})
+	}
+	return out, nil
+}
@@ -0,0 +1,234 @@
+package tilemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type tmjProperty struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+}
+
+type tmjObject struct {
+	ID         int           `json:"id"`
+	Name       string        `json:"name"`
+	Type       string        `json:"type"`
+	Class      string        `json:"class"`
+	X          float64       `json:"x"`
+	Y          float64       `json:"y"`
+	Width      float64       `json:"width"`
+	Height     float64       `json:"height"`
+	Properties []tmjProperty `json:"properties"`
+}
+
+type tmjLayer struct {
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	Visible     *bool           `json:"visible"`
+	Data        json.RawMessage `json:"data"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Objects     []tmjObject     `json:"objects"`
+	Layers      []tmjLayer      `json:"layers"`
+	Properties  []tmjProperty   `json:"properties"`
+}
+
+type tmjTile struct {
+	ID         int           `json:"id"`
+	Properties []tmjProperty `json:"properties"`
+}
+
+type tmjTileset struct {
+	FirstGID int       `json:"firstgid"`
+	Source   string    `json:"source"`
+	Tiles    []tmjTile `json:"tiles"`
+}
+
+type tmjMap struct {
+	Width      int           `json:"width"`
+	Height     int           `json:"height"`
+	TileWidth  int           `json:"tilewidth"`
+	TileHeight int           `json:"tileheight"`
+	Infinite   bool          `json:"infinite"`
+	Layers     []tmjLayer    `json:"layers"`
+	Tilesets   []tmjTileset  `json:"tilesets"`
+	Properties []tmjProperty `json:"properties"`
+}
+
+func decodeTMJ(path string) (*tiledDoc, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw tmjMap
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	dir := filepath.Dir(path)
+	doc := &tiledDoc{
+		width:    raw.Width,
+		height:   raw.Height,
+		tileW:    raw.TileWidth,
+		tileH:    raw.TileHeight,
+		infinite: raw.Infinite,
+		props:    tmjProps(raw.Properties),
+	}
+	for _, ts := range raw.Tilesets {
+		tileset, err := decodeTMJTileset(ts, dir)
+		if err != nil {
+			return nil, err
+		}
+		doc.tilesets = append(doc.tilesets, tileset)
+	}
+	if err := appendTMJLayers(doc, raw.Layers); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func decodeTMJTileset(ts tmjTileset, dir string) (tiledTileset, error) {
+	if ts.Source != "" {
+		source := filepath.Join(dir, ts.Source)
+		if strings.EqualFold(filepath.Ext(source), ".tsx") {
+			return decodeTSXFile(source, ts.FirstGID)
+		}
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return tiledTileset{}, err
+		}
+		var ext tmjTileset
+		if err := json.Unmarshal(data, &ext); err != nil {
+			return tiledTileset{}, fmt.Errorf("parse %s: %w", source, err)
+		}
+		ext.FirstGID = ts.FirstGID
+		ext.Source = ""
+		return decodeTMJTileset(ext, filepath.Dir(source))
+	}
+	out := tiledTileset{firstGID: ts.FirstGID, dir: dir, tiles: map[int]Props{}}
+	for _, t := range ts.Tiles {
+		out.tiles[t.ID] = tmjProps(t.Properties)
+	}
+	return out, nil
+}
+
+func appendTMJLayers(doc *tiledDoc, layers []tmjLayer) error {
+	for _, l := range layers {
+		visible := l.Visible == nil || *l.Visible
+		switch l.Type {
+		case "group":
+			if err := appendTMJLayers(doc, l.Layers); err != nil {
+				return err
+			}
+		case "tilelayer":
+			data, err := decodeTMJData(l)
+			if err != nil {
+				return fmt.Errorf("layer %q: %w", l.Name, err)
+			}
+			doc.layers = append(doc.layers, tiledLayer{name: l.Name, visible: visible, props: tmjProps(l.Properties), data: data})
+		case "objectgroup":
+			layer := tiledLayer{object: true, name: l.Name, visible: visible, props: tmjProps(l.Properties)}
+			for _, o := range l.Objects {
+				typ := o.Type
+				if typ == "" {
+					typ = o.Class
+				}
+				layer.objects = append(layer.objects, Spawn{
+					ID:    o.ID,
+					Name:  o.Name,
+					Type:  typ,
+					X:     o.X,
+					Y:     o.Y,
+					W:     o.Width,
+					H:     o.Height,
+					Props: tmjProps(o.Properties),
+				})
+			}
+			doc.layers = append(doc.layers, layer)
+		}
+	}
+	return nil
+}
+
+func decodeTMJData(l tmjLayer) ([]uint32, error) {
+	if l.Encoding != "base64" {
+		var gids []uint32
+		if err := json.Unmarshal(l.Data, &gids); err != nil {
+			return nil, err
+		}
+		return gids, nil
+	}
+	var text string
+	if err := json.Unmarshal(l.Data, &text); err != nil {
+		return nil, err
+	}
+	return decodeBase64GIDs(text, l.Compression)
+}
+
+// decodeBase64GIDs decodes little-endian uint32 tile ids from base64 data
+// shared by the JSON and XML formats.
+func decodeBase64GIDs(text, compression string) ([]uint32, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+	if err != nil {
+		return nil, err
+	}
+	var r io.Reader = bytes.NewReader(raw)
+	switch compression {
+	case "":
+	case "gzip":
+		if r, err = gzip.NewReader(r); err != nil {
+			return nil, err
+		}
+	case "zlib":
+		if r, err = zlib.NewReader(r); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", compression)
+	}
+	raw, err = io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw)%4 != 0 {
+		return nil, fmt.Errorf("tile data length %d is not a multiple of 4", len(raw))
+	}
+	gids := make([]uint32, len(raw)/4)
+	for i := range gids {
+		gids[i] = binary.LittleEndian.Uint32(raw[i*4:])
+	}
+	return gids, nil
+}
+
+func tmjProps(in []tmjProperty) Props {
+	if len(in) == 0 {
+		return nil
+	}
+	out := Props{}
+	for _, p := range in {
+		switch v := p.Value.(type) {
+		case string:
+			out[p.Name] = v
+		case bool:
+			out[p.Name] = strconv.FormatBool(v)
+		case float64:
+			out[p.Name] = strconv.FormatFloat(v, 'f', -1, 64)
+		case nil:
+			out[p.Name] = ""
+		default:
+			out[p.Name] = fmt.Sprint(v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,50 @@
+package tilemap
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestLoadTiled verifies JSON and XML maps import to the same layers, tiles and spawns.
+func TestLoadTiled(t *testing.T) {
+	for _, name := range []string{"level.tmj", "level.tmx"} {
+		t.Run(name, func(t *testing.T) {
+			tm, err := LoadTiled(filepath.Join("testdata", name))
+			if err != nil {
+				t.Fatalf("LoadTiled: %v", err)
+			}
+			if tm.Props["music"] != "cave" {
+				t.Fatalf("map props=%v", tm.Props)
+			}
+			if tm.TileW != 2 || tm.TileH != 1 {
+				t.Fatalf("tile size=%dx%d want=2x1", tm.TileW, tm.TileH)
+			}
+
+			ground := tm.Layer("ground")
+			if ground == nil {
+				t.Fatalf("missing ground layer")
+			}
+			if ground.At(0, 0) != 1 || ground.At(1, 1) != 1 || ground.At(2, 0) != 2 || ground.At(1, 0) != 0 {
+				t.Fatalf("tiles=%v", ground.Tiles)
+			}
+			if ground.Tileset[1] == nil || ground.Tileset[2] != nil {
+				t.Fatalf("only tile 1 should have a sprite")
+			}
+			if !ground.Props(0, 0).Bool("solid", false) || ground.Props(2, 0).Int("cost", 0) != 3 {
+				t.Fatalf("props=%v %v", ground.Props(0, 0), ground.Props(2, 0))
+			}
+			if ground.Props(0, 0).Has("sprite") {
+				t.Fatalf("sprite property should not be copied to tile props")
+			}
+
+			players := tm.SpawnsOf("player")
+			if len(players) != 1 {
+				t.Fatalf("spawns=%v", tm.Spawns)
+			}
+			hero := players[0]
+			if hero.Name != "hero" || hero.Layer != "spawns" || hero.X != 4 || hero.Y != 1 || hero.Props.Int("hp", 0) != 5 {
+				t.Fatalf("hero=%+v", hero)
+			}
+		})
+	}
+}
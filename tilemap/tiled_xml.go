@@ -0,0 +1,219 @@
+package tilemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type tmxProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+	Text  string `xml:",chardata"`
+}
+
+type tmxProperties struct {
+	Items []tmxProperty `xml:"property"`
+}
+
+type tmxObject struct {
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Width      float64       `xml:"width,attr"`
+	Height     float64       `xml:"height,attr"`
+	Properties tmxProperties `xml:"properties"`
+}
+
+type tmxData struct {
+	Encoding    string `xml:"encoding,attr"`
+	Compression string `xml:"compression,attr"`
+	Text        string `xml:",chardata"`
+	Tiles       []struct {
+		GID uint32 `xml:"gid,attr"`
+	} `xml:"tile"`
+}
+
+// tmxLayer covers <layer>, <objectgroup> and <group>, which may be
+// interleaved; XMLName tells them apart while preserving document order.
+type tmxLayer struct {
+	XMLName    xml.Name
+	Name       string        `xml:"name,attr"`
+	Visible    *int          `xml:"visible,attr"`
+	Data       tmxData       `xml:"data"`
+	Objects    []tmxObject   `xml:"object"`
+	Layers     []tmxLayer    `xml:",any"`
+	Properties tmxProperties `xml:"properties"`
+}
+
+type tmxTile struct {
+	ID         int           `xml:"id,attr"`
+	Properties tmxProperties `xml:"properties"`
+}
+
+type tmxTileset struct {
+	FirstGID int       `xml:"firstgid,attr"`
+	Source   string    `xml:"source,attr"`
+	Tiles    []tmxTile `xml:"tile"`
+}
+
+type tmxMap struct {
+	Width      int           `xml:"width,attr"`
+	Height     int           `xml:"height,attr"`
+	TileWidth  int           `xml:"tilewidth,attr"`
+	TileHeight int           `xml:"tileheight,attr"`
+	Infinite   int           `xml:"infinite,attr"`
+	Tilesets   []tmxTileset  `xml:"tileset"`
+	Layers     []tmxLayer    `xml:",any"`
+	Properties tmxProperties `xml:"properties"`
+}
+
+func decodeTMX(path string) (*tiledDoc, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw tmxMap
+	if err := xml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	dir := filepath.Dir(path)
+	doc := &tiledDoc{
+		width:    raw.Width,
+		height:   raw.Height,
+		tileW:    raw.TileWidth,
+		tileH:    raw.TileHeight,
+		infinite: raw.Infinite != 0,
+		props:    tmxProps(raw.Properties),
+	}
+	for _, ts := range raw.Tilesets {
+		var tileset tiledTileset
+		if ts.Source != "" {
+			source := filepath.Join(dir, ts.Source)
+			if strings.EqualFold(filepath.Ext(source), ".tsj") || strings.EqualFold(filepath.Ext(source), ".json") {
+				tileset, err = decodeTMJTileset(tmjTileset{FirstGID: ts.FirstGID, Source: ts.Source}, dir)
+			} else {
+				tileset, err = decodeTSXFile(source, ts.FirstGID)
+			}
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			tileset = tmxTilesetProps(ts, dir)
+		}
+		doc.tilesets = append(doc.tilesets, tileset)
+	}
+	if err := appendTMXLayers(doc, raw.Layers); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func decodeTSXFile(path string, firstGID int) (tiledTileset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return tiledTileset{}, err
+	}
+	var ts tmxTileset
+	if err := xml.Unmarshal(data, &ts); err != nil {
+		return tiledTileset{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	ts.FirstGID = firstGID
+	return tmxTilesetProps(ts, filepath.Dir(path)), nil
+}
+
+func tmxTilesetProps(ts tmxTileset, dir string) tiledTileset {
+	out := tiledTileset{firstGID: ts.FirstGID, dir: dir, tiles: map[int]Props{}}
+	for _, t := range ts.Tiles {
+		out.tiles[t.ID] = tmxProps(t.Properties)
+	}
+	return out
+}
+
+func appendTMXLayers(doc *tiledDoc, layers []tmxLayer) error {
+	for _, l := range layers {
+		visible := l.Visible == nil || *l.Visible != 0
+		switch l.XMLName.Local {
+		case "group":
+			if err := appendTMXLayers(doc, l.Layers); err != nil {
+				return err
+			}
+		case "layer":
+			data, err := decodeTMXData(l.Data)
+			if err != nil {
+				return fmt.Errorf("layer %q: %w", l.Name, err)
+			}
+			doc.layers = append(doc.layers, tiledLayer{name: l.Name, visible: visible, props: tmxProps(l.Properties), data: data})
+		case "objectgroup":
+			layer := tiledLayer{object: true, name: l.Name, visible: visible, props: tmxProps(l.Properties)}
+			for _, o := range l.Objects {
+				typ := o.Type
+				if typ == "" {
+					typ = o.Class
+				}
+				layer.objects = append(layer.objects, Spawn{
+					ID:    o.ID,
+					Name:  o.Name,
+					Type:  typ,
+					X:     o.X,
+					Y:     o.Y,
+					W:     o.Width,
+					H:     o.Height,
+					Props: tmxProps(o.Properties),
+				})
+			}
+			doc.layers = append(doc.layers, layer)
+		}
+	}
+	return nil
+}
+
+func decodeTMXData(d tmxData) ([]uint32, error) {
+	switch d.Encoding {
+	case "csv":
+		var gids []uint32
+		for _, field := range strings.Split(d.Text, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			v, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			gids = append(gids, uint32(v))
+		}
+		return gids, nil
+	case "base64":
+		return decodeBase64GIDs(d.Text, d.Compression)
+	case "":
+		gids := make([]uint32, len(d.Tiles))
+		for i, t := range d.Tiles {
+			gids[i] = t.GID
+		}
+		return gids, nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q", d.Encoding)
+	}
+}
+
+func tmxProps(in tmxProperties) Props {
+	if len(in.Items) == 0 {
+		return nil
+	}
+	out := Props{}
+	for _, p := range in.Items {
+		v := p.Value
+		if v == "" {
+			v = strings.TrimSpace(p.Text)
+		}
+		out[p.Name] = v
+	}
+	return out
+}
@@ -4,16 +4,37 @@ import (
 	"math"
 
 	"github.com/dgrundel/glif/camera"
+	"github.com/dgrundel/glif/grid"
 	"github.com/dgrundel/glif/render"
 )
 
 type Map struct {
-	W, H    int
-	TileW   int
-	TileH   int
-	Empty   int
-	Tiles   []int
-	Tileset map[int]*render.Sprite
+	W, H       int
+	TileW      int
+	TileH      int
+	Empty      int
+	Tiles      []int
+	Tileset    map[int]*render.Sprite
+	TileProps  map[int]Props
+	Animations map[int]*TileAnimation
+	Autotiles  map[int]*Autotile
+	// Mapping is set by LoadFromFiles and used by Save.
+	Mapping *TilesetMapping
+	// MapPath and TilesPath are the files the map was loaded from by
+	// LoadFromFiles or LoadLevel, if any.
+	MapPath   string
+	TilesPath string
+	// EntitiesPath is the `.entities` file LoadLevel read, if any, and
+	// EntityRunes the entity runes it found in the map by cell index
+	// (y*W+x). Save writes those runes back in place of their tiles.
+	EntitiesPath string
+	EntityRunes  map[int]rune
+
+	version  int
+	variants []int
+	clock    float64
+	phases   map[int]float64
+	summary  map[*render.Sprite]grid.Cell
 }
 
 func New(w, h, tileW, tileH, empty int) *Map {
@@ -30,25 +51,44 @@ func New(w, h, tileW, tileH, empty int) *Map {
 		tileH = 1
 	}
 	return &Map{
-		W:       w,
-		H:       h,
-		TileW:   tileW,
-		TileH:   tileH,
-		Empty:   empty,
-		Tiles:   make([]int, w*h),
-		Tileset: map[int]*render.Sprite{},
+		W:          w,
+		H:          h,
+		TileW:      tileW,
+		TileH:      tileH,
+		Empty:      empty,
+		Tiles:      make([]int, w*h),
+		Tileset:    map[int]*render.Sprite{},
+		TileProps:  map[int]Props{},
+		Animations: map[int]*TileAnimation{},
+		Autotiles:  map[int]*Autotile{},
 	}
 }
 
 func (m *Map) InBounds(x, y int) bool {
-	return x >= 0 && y >= 0 && x < m.W && y < m.H
+	return m != nil && x >= 0 && y >= 0 && x < m.W && y < m.H
 }
 
 func (m *Map) Set(x, y, id int) {
 	if !m.InBounds(x, y) {
 		return
 	}
-	m.Tiles[y*m.W+x] = id
+	idx := y*m.W + x
+	if m.Tiles[idx] == id {
+		return
+	}
+	m.Tiles[idx] = id
+	m.version++
+	m.refreshAround(x, y)
+}
+
+// Version changes whenever Set changes a tile. Caches built from the map
+// can compare it to decide when to rebuild. Writes made directly to Tiles
+// are not tracked.
+func (m *Map) Version() int {
+	if m == nil {
+		return 0
+	}
+	return m.version
 }
 
 func (m *Map) At(x, y int) int {
@@ -71,19 +111,27 @@ func (m *Map) WorldBounds() camera.Bounds {
 }
 
 func (m *Map) Draw(r *render.Renderer, worldX, worldY float64) {
+	m.DrawWith(r, worldX, worldY, nil)
+}
+
+// DrawWith draws the map like Draw, but passes each tile sprite through fn
+// first. fn may return a different sprite, or nil to skip the tile.
+func (m *Map) DrawWith(r *render.Renderer, worldX, worldY float64, fn func(tx, ty int, sprite *render.Sprite) *render.Sprite) {
 	if m == nil || r == nil {
 		return
 	}
 	for ty := 0; ty < m.H; ty++ {
 		for tx := 0; tx < m.W; tx++ {
-			id := m.At(tx, ty)
-			if id == m.Empty {
-				continue
-			}
-			sprite := m.Tileset[id]
+			sprite := m.Sprite(tx, ty)
 			if sprite == nil {
 				continue
 			}
+			if fn != nil {
+				sprite = fn(tx, ty, sprite)
+				if sprite == nil {
+					continue
+				}
+			}
 			wx := worldX + float64(tx*m.TileW)
 			wy := worldY + float64(ty*m.TileH)
 			r.DrawSprite(int(math.Floor(wx)), int(math.Floor(wy)), sprite)
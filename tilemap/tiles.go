@@ -0,0 +1,43 @@
+package tilemap
+
+import "github.com/dgrundel/glif/render"
+
+// Tiles is read-only access to a grid of tiles, implemented by Map and
+// ChunkedMap. The path, collision and fov packages accept it, so they work
+// with either. Reads never generate chunks.
+type Tiles interface {
+	// InBounds reports whether x,y holds a tile.
+	InBounds(x, y int) bool
+	// At returns the tile id at x,y, or the empty id outside the bounds.
+	At(x, y int) int
+	Props(x, y int) Props
+	// Sprite returns the sprite drawn at x,y, or nil for empty tiles.
+	Sprite(x, y int) *render.Sprite
+	// TileSize is the size of one tile in world cells.
+	TileSize() (w, h int)
+	// Extent is the smallest rectangle of tile coordinates holding every
+	// in-bounds tile.
+	Extent() (x, y, w, h int)
+	// Version changes whenever a tile or the set of tiles changes.
+	Version() int
+}
+
+var (
+	_ Tiles = (*Map)(nil)
+	_ Tiles = (*ChunkedMap)(nil)
+)
+
+func (m *Map) TileSize() (int, int) {
+	if m == nil {
+		return 1, 1
+	}
+	return m.TileW, m.TileH
+}
+
+// Extent is 0,0 to the map's size.
+func (m *Map) Extent() (x, y, w, h int) {
+	if m == nil {
+		return 0, 0, 0, 0
+	}
+	return 0, 0, m.W, m.H
+}